@@ -0,0 +1,60 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requireAdmin only lets requests carrying the configured admin token through
+func (a *API) requireAdmin(c *fiber.Ctx) error {
+	token := []byte("Bearer " + a.dep.Config.AdminToken)
+	if subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), token) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	return c.Next()
+}
+
+type FailedBlobResponse struct {
+	VersionedHash string    `json:"versioned_hash"`
+	BlockNumber   int64     `json:"block_number"`
+	BlobIndex     int32     `json:"blob_index"`
+	Error         string    `json:"error"`
+	Attempts      int32     `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// GetFailedBlobs lists the blobs waiting to be retried by the indexer
+func (a *API) GetFailedBlobs(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 100)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || limit > 1000 || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit or offset"})
+	}
+	failed, err := a.queries.ListFailedBlobs(c.Context(), dbgen.ListFailedBlobsParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]FailedBlobResponse, len(failed))
+	for i, f := range failed {
+		resp[i] = FailedBlobResponse{
+			VersionedHash: "0x" + hex.EncodeToString(f.VersionedHash),
+			BlockNumber:   f.BlockNumber,
+			BlobIndex:     f.BlobIndex,
+			Error:         f.Error,
+			Attempts:      f.Attempts,
+			NextAttemptAt: f.NextAttemptAt,
+			CreatedAt:     f.CreatedAt,
+			UpdatedAt:     f.UpdatedAt,
+		}
+	}
+	return c.JSON(resp)
+}
@@ -24,13 +24,23 @@ func NewAPI(dep *dependencies.Dependencies) *API {
 	}
 
 	// Add CORS middleware to allow all origins
-	api.app.Use(cors.New())
+	api.app.Use(cors.New(cors.Config{
+		ExposeHeaders: nextOffsetHeader,
+	}))
 
 	api.app.Get("/keys", api.GetKeys)
 	api.app.Get("/messages/:index", api.GetMessage)
 	api.app.Post("/messages", api.PostMessage)
+	api.app.Post("/messages/lookup", api.LookupMessages)
+	api.app.Get("/submissions/:id", api.GetSubmission)
 	api.app.Post("/ens", api.RegisterENS)
 	api.app.Get("/ens/:address", api.GetENS)
+
+	// admin routes are only served if an admin token is configured
+	if dep.Config.AdminToken != "" {
+		admin := api.app.Group("/admin", api.requireAdmin)
+		admin.Get("/failed-blobs", api.GetFailedBlobs)
+	}
 	return api
 }
 
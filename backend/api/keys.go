@@ -1,29 +1,140 @@
 package api
 
 import (
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+const (
+	defaultKeysLimit = 1000
+	maxKeysLimit     = 10000
+)
+
+type KeyResponse struct {
+	Pubkey     string    `json:"pubkey"`
+	SubmitTime time.Time `json:"submit_time"`
+	// block the key was first seen on chain in, unset for keys only known to this relay
+	BlockNumber *int64 `json:"block_number,omitempty"`
+}
+
+type KeysResponse struct {
+	Keys []KeyResponse `json:"keys"`
+	// pass as cursor to get the next page, unset once all keys were returned
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// GetKeys pages through the ephemeral keys ordered by (submit_time, pubkey).
+// The first page starts after since, with the keys seen on chain at or after
+// block, or both, later pages continue from the cursor of the previous page.
 func (a *API) GetKeys(c *fiber.Ctx) error {
-	since := c.Query("since")
-	sinceTime, err := time.Parse(time.RFC3339, since)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	limit := c.QueryInt("limit", defaultKeysLimit)
+	if limit <= 0 || limit > maxKeysLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit"})
+	}
+	params := dbgen.GetPubkeysPageParams{
+		AfterPubkey: []byte{},
+		PageSize:    int32(limit + 1),
+	}
+	switch {
+	case c.Query("cursor") != "":
+		// the cursor carries the filter of the first page, since is implied
+		// as the position of the last key is past it
+		cursor, err := decodeKeysCursor(c.Query("cursor"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		params.AfterTime = cursor.submitTime
+		params.AfterPubkey = cursor.pubkey
+		params.FromBlock = cursor.fromBlock
+	default:
+		if block := c.QueryInt("block", -1); block >= 0 {
+			fromBlock := int64(block)
+			params.FromBlock = &fromBlock
+		}
+		if c.Query("since") != "" {
+			since, err := time.Parse(time.RFC3339, c.Query("since"))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			}
+			params.AfterTime = since
+			params.Since = &since
+		} else if params.FromBlock == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "One of since, block or cursor is required"})
+		}
 	}
-	keys, err := a.queries.GetPubkeysSince(c.Context(), sinceTime)
+
+	keys, err := a.queries.GetPubkeysPage(c.Context(), params)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
-	keyString := make([]string, len(keys))
+	var resp KeysResponse
+	if len(keys) > limit {
+		keys = keys[:limit]
+		last := keys[len(keys)-1]
+		resp.NextCursor = encodeKeysCursor(keysCursor{last.SubmitTime, last.Pubkey, params.FromBlock})
+	}
+	resp.Keys = make([]KeyResponse, len(keys))
 	for i, key := range keys {
-		keyString[i] = hex.EncodeToString(key.Pubkey)
+		resp.Keys[i] = KeyResponse{
+			Pubkey:      hex.EncodeToString(key.Pubkey),
+			SubmitTime:  key.SubmitTime,
+			BlockNumber: key.BlockNumber,
+		}
+	}
+	return c.JSON(resp)
+}
+
+// keysCursor is the position of the last returned key and the block filter
+// of the first page
+type keysCursor struct {
+	submitTime time.Time
+	pubkey     []byte
+	fromBlock  *int64
+}
+
+// the cursor is encoded opaquely so clients do not depend on its format
+func encodeKeysCursor(cursor keysCursor) string {
+	raw := cursor.submitTime.UTC().Format(time.RFC3339Nano) + "_" + hex.EncodeToString(cursor.pubkey) + "_"
+	if cursor.fromBlock != nil {
+		raw += strconv.FormatInt(*cursor.fromBlock, 10)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeKeysCursor(encoded string) (keysCursor, error) {
+	invalid := errors.New("invalid cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return keysCursor{}, invalid
+	}
+	parts := strings.Split(string(raw), "_")
+	if len(parts) != 3 {
+		return keysCursor{}, invalid
+	}
+	var cursor keysCursor
+	cursor.submitTime, err = time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return keysCursor{}, invalid
+	}
+	cursor.pubkey, err = hex.DecodeString(parts[1])
+	if err != nil {
+		return keysCursor{}, invalid
+	}
+	if parts[2] != "" {
+		fromBlock, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || fromBlock < 0 {
+			return keysCursor{}, invalid
+		}
+		cursor.fromBlock = &fromBlock
 	}
-	return c.JSON(keyString)
+	return cursor, nil
 }
@@ -5,7 +5,9 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"proto-dankmessaging/backend/blob"
 	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"strconv"
 	"time"
 
 	"github.com/go-playground/validator"
@@ -19,6 +21,10 @@ type PostMessageRequest struct {
 	Message         string `json:"message" validate:"required,base64"`
 }
 
+type PostMessageResponse struct {
+	SubmissionID int32 `json:"submission_id"`
+}
+
 type PostMessageRequestBytes struct {
 	EphemeralPubKey []byte `json:"ephemeral_pubkey"`
 	SearchIndex     []byte `json:"search_index"`
@@ -40,11 +46,23 @@ func (a *API) PostMessage(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
-	err = a.bypassBlob(c.Context(), requestBytes)
+	fits := blob.FitsBlob(dbgen.MessageBlobSubmission{
+		Index:   requestBytes.SearchIndex,
+		Message: requestBytes.Message,
+		Pubkey:  requestBytes.EphemeralPubKey,
+	})
+	if !fits {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "Message does not fit into a blob"})
+	}
+	// the submitter may claim the submission right away, its pending
+	// message has to exist by then so it is marked submitted along with it
+	dbTx, err := a.dep.DB.Pool().Begin(c.Context())
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to add directly to the database")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
-	_, err = a.queries.AddBlobSubmission(c.Context(), dbgen.AddBlobSubmissionParams{
+	defer dbTx.Rollback(c.Context())
+	qtx := a.queries.WithTx(dbTx)
+	submission, err := qtx.AddBlobSubmission(c.Context(), dbgen.AddBlobSubmissionParams{
 		Index:   requestBytes.SearchIndex,
 		Message: requestBytes.Message,
 		Pubkey:  requestBytes.EphemeralPubKey,
@@ -53,7 +71,16 @@ func (a *API) PostMessage(c *fiber.Ctx) error {
 		log.Error().Err(err).Msg("Failed to add blob submission")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.SendStatus(fiber.StatusOK)
+	err = bypassBlob(c.Context(), qtx, requestBytes, submission.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to add directly to the database")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	err = dbTx.Commit(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(PostMessageResponse{SubmissionID: submission.ID})
 }
 
 func convertPostMessageRequestToBytes(request PostMessageRequest) (PostMessageRequestBytes, error) {
@@ -76,11 +103,47 @@ func convertPostMessageRequestToBytes(request PostMessageRequest) (PostMessageRe
 	}, nil
 }
 
+type MessageStatus string
+
+const (
+	// only known to this relay, waiting to be sent in a blob
+	MessageStatusPending MessageStatus = "pending"
+	// sent in a blob transaction that is not included yet
+	MessageStatusSubmitted MessageStatus = "submitted"
+	MessageStatusOnChain   MessageStatus = "on_chain"
+	MessageStatusFinalized MessageStatus = "finalized"
+)
+
+var messageStatuses = map[dbgen.MessageBlobState]MessageStatus{
+	dbgen.MessageBlobStatePending:   MessageStatusPending,
+	dbgen.MessageBlobStateSubmitted: MessageStatusSubmitted,
+	dbgen.MessageBlobStateOnChain:   MessageStatusOnChain,
+	dbgen.MessageBlobStateFinalized: MessageStatusFinalized,
+}
+
 type MessageResponse struct {
-	Message    []byte    `json:"message"`
-	SubmitTime time.Time `json:"submit_time"`
+	Message    []byte        `json:"message"`
+	SubmitTime time.Time     `json:"submit_time"`
+	Status     MessageStatus `json:"status"`
+	// where the message was found on chain, empty for messages that were
+	// not ingested from a blob yet
+	TxHash        string `json:"tx_hash,omitempty"`
+	VersionedHash string `json:"versioned_hash,omitempty"`
+	BlockNumber   *int64 `json:"block_number,omitempty"`
+	BlockHash     string `json:"block_hash,omitempty"`
+	// position of the message within the BlobContent of the blob
+	BlobPosition *int32 `json:"blob_position,omitempty"`
 }
 
+const (
+	defaultMessagesLimit = 100
+	maxMessagesLimit     = 1000
+	// set to the offset of the next page if there are more messages
+	nextOffsetHeader = "X-Next-Offset"
+)
+
+// GetMessage returns the messages of an index in chain order, messages that
+// are not on chain yet come last. Pages are selected with limit and offset.
 func (a *API) GetMessage(c *fiber.Ctx) error {
 	index := c.Params("index")
 	if index == "" {
@@ -90,23 +153,120 @@ func (a *API) GetMessage(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid index: " + err.Error()})
 	}
-	messages, err := a.queries.GetMessagesByIndex(c.Context(), indexBytes)
+	limit := c.QueryInt("limit", defaultMessagesLimit)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || limit > maxMessagesLimit || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit or offset"})
+	}
+	// fetch one more message to know whether there is another page
+	messages, err := a.queries.GetMessagesByIndex(c.Context(), dbgen.GetMessagesByIndexParams{
+		Index:  indexBytes,
+		States: a.visibleStates(c.QueryBool("confirmed")),
+		Limit:  int32(limit + 1),
+		Offset: int32(offset),
+	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		c.Set(nextOffsetHeader, strconv.Itoa(offset+limit))
+	}
 	messageResponses := make([]MessageResponse, len(messages))
 	for i, message := range messages {
-		messageResponses[i] = MessageResponse{
-			Message:    message.Message,
-			SubmitTime: message.SubmitTime,
-		}
+		messageResponses[i] = newMessageResponse(message)
 	}
 	return c.JSON(messageResponses)
 }
 
+// visibleStates returns the message states served to clients, confirmed
+// only returns messages that are on chain
+func (a *API) visibleStates(confirmed bool) []string {
+	if a.dep.Config.FinalizedOnly {
+		return []string{string(dbgen.MessageBlobStateFinalized)}
+	}
+	if confirmed {
+		return []string{string(dbgen.MessageBlobStateOnChain), string(dbgen.MessageBlobStateFinalized)}
+	}
+	states := make([]string, 0, len(dbgen.AllMessageBlobStateValues()))
+	for _, state := range dbgen.AllMessageBlobStateValues() {
+		states = append(states, string(state))
+	}
+	return states
+}
+
+type LookupMessagesRequest struct {
+	// at most 10000 hex encoded search indexes
+	SearchIndexes []string `json:"search_indexes" validate:"required,min=1,max=10000,dive,hexadecimal"`
+	// only return messages that are on chain
+	Confirmed bool `json:"confirmed"`
+}
+
+type LookupMessagesResponse struct {
+	// messages per hex encoded search index in chain order, indexes without
+	// messages are left out
+	Messages map[string][]MessageResponse `json:"messages"`
+}
+
+// LookupMessages returns the messages of many search indexes at once, so
+// clients scanning their inbox do not need a request per index
+func (a *API) LookupMessages(c *fiber.Ctx) error {
+	var request LookupMessagesRequest
+	err := c.BodyParser(&request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	indexes := make([][]byte, len(request.SearchIndexes))
+	for i, index := range request.SearchIndexes {
+		indexes[i], err = hex.DecodeString(index)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid index: " + err.Error()})
+		}
+	}
+	messages, err := a.queries.GetMessagesByIndexes(c.Context(), dbgen.GetMessagesByIndexesParams{
+		Indexes: indexes,
+		States:  a.visibleStates(request.Confirmed),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := LookupMessagesResponse{Messages: make(map[string][]MessageResponse)}
+	for _, message := range messages {
+		index := hex.EncodeToString(message.Index)
+		resp.Messages[index] = append(resp.Messages[index], newMessageResponse(message))
+	}
+	return c.JSON(resp)
+}
+
+func newMessageResponse(message dbgen.MessageBlob) MessageResponse {
+	return MessageResponse{
+		Message:       message.Message,
+		SubmitTime:    message.SubmitTime,
+		Status:        messageStatuses[message.State],
+		TxHash:        hexOrEmpty(message.TxHash),
+		VersionedHash: hexOrEmpty(message.VersionedHash),
+		BlockNumber:   message.BlockNumber,
+		BlockHash:     hexOrEmpty(message.BlockHash),
+		BlobPosition:  message.BlobPosition,
+	}
+}
+
+// hexOrEmpty encodes b as 0x prefixed hex, nil stays empty
+func hexOrEmpty(b []byte) string {
+	if b == nil {
+		return ""
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
 // will add it directly to the database makes the whole process faster but can not test blobs using this
-func (a *API) bypassBlob(ctx context.Context, msg PostMessageRequestBytes) error {
-	_, err := a.queries.AddPubkey(ctx, dbgen.AddPubkeyParams{
+func bypassBlob(ctx context.Context, qtx *dbgen.Queries, msg PostMessageRequestBytes, submissionID int32) error {
+	_, err := qtx.AddPubkey(ctx, dbgen.AddPubkeyParams{
 		Pubkey:     msg.EphemeralPubKey,
 		SubmitTime: time.Now(),
 	})
@@ -114,11 +274,12 @@ func (a *API) bypassBlob(ctx context.Context, msg PostMessageRequestBytes) error
 		log.Error().Err(err).Msg("failed to add pubkey")
 		return errors.New("failed to add pubkey: " + err.Error())
 	}
-	_, err = a.queries.AddMessage(ctx, dbgen.AddMessageParams{
-		Index:           msg.SearchIndex,
-		Message:         msg.Message,
-		SubmitTime:      time.Now(),
-		NeedsSubmission: true,
+	_, err = qtx.AddMessage(ctx, dbgen.AddMessageParams{
+		Index:        msg.SearchIndex,
+		Message:      msg.Message,
+		SubmitTime:   time.Now(),
+		State:        dbgen.MessageBlobStatePending,
+		SubmissionID: &submissionID,
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("failed to add message")
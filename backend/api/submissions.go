@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+type SubmissionStatus string
+
+const (
+	SubmissionStatusQueued    SubmissionStatus = "queued"
+	SubmissionStatusInFlight  SubmissionStatus = "in_flight"
+	SubmissionStatusIncluded  SubmissionStatus = "included"
+	SubmissionStatusFinalized SubmissionStatus = "finalized"
+	SubmissionStatusFailed    SubmissionStatus = "failed"
+)
+
+var submissionStatuses = map[dbgen.MessageSubmissionState]SubmissionStatus{
+	dbgen.MessageSubmissionStateQueued:    SubmissionStatusQueued,
+	dbgen.MessageSubmissionStateInFlight:  SubmissionStatusInFlight,
+	dbgen.MessageSubmissionStateConfirmed: SubmissionStatusIncluded,
+	dbgen.MessageSubmissionStateFinalized: SubmissionStatusFinalized,
+	dbgen.MessageSubmissionStateFailed:    SubmissionStatusFailed,
+}
+
+type SubmissionResponse struct {
+	ID            int32            `json:"id"`
+	Status        SubmissionStatus `json:"status"`
+	TxHash        string           `json:"tx_hash,omitempty"`
+	VersionedHash string           `json:"versioned_hash,omitempty"`
+	BlockNumber   *int64           `json:"block_number,omitempty"`
+	Error         *string          `json:"error,omitempty"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	// how full the blob of the submission went out and why, unset until it
+	// was flushed
+	Batch *BatchResponse `json:"batch,omitempty"`
+}
+
+type BatchResponse struct {
+	MessageCount int32   `json:"message_count"`
+	FillRatio    float64 `json:"fill_ratio"`
+	WaitMs       int64   `json:"wait_ms"`
+	Trigger      string  `json:"trigger"`
+}
+
+func (a *API) GetSubmission(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid submission id"})
+	}
+	submission, err := a.queries.GetBlobSubmission(c.Context(), int32(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Submission not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := SubmissionResponse{
+		ID:            submission.ID,
+		Status:        submissionStatuses[submission.State],
+		TxHash:        hexOrEmpty(submission.TxHash),
+		VersionedHash: hexOrEmpty(submission.VersionedHash),
+		BlockNumber:   submission.BlockNumber,
+		Error:         submission.Error,
+		UpdatedAt:     submission.UpdatedAt,
+	}
+	if submission.VersionedHash != nil {
+		batch, err := a.queries.GetBlobBatch(c.Context(), submission.VersionedHash)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err == nil {
+			resp.Batch = &BatchResponse{
+				MessageCount: batch.MessageCount,
+				FillRatio:    batch.FillRatio,
+				WaitMs:       batch.WaitMs,
+				Trigger:      string(batch.Trigger),
+			}
+		}
+	}
+	return c.JSON(resp)
+}
@@ -0,0 +1,143 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"proto-dankmessaging/backend/dependencies/config"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// BlobArchive keeps the raw blobs of the relay after consensus clients pruned
+// them, an archive is a BlobSource itself so the relay can re-index from it
+type BlobArchive interface {
+	BlobSource
+	// Put stores the blob, storing the same blob again overwrites it
+	Put(ctx context.Context, blob *SourceBlob) error
+	// Remove deletes the blob, removing a missing blob is not an error
+	Remove(ctx context.Context, blob *SourceBlob) error
+}
+
+// newBlobArchive returns nil if no archive store is configured
+func newBlobArchive(c *config.Config) (BlobArchive, error) {
+	switch c.ArchiveStore {
+	case "":
+		return nil, nil
+	case config.ArchiveStoreLocal:
+		return NewLocalSource(c.ArchiveDir), nil
+	case config.ArchiveStoreS3:
+		return NewS3Archive(
+			c.ArchiveS3Endpoint,
+			c.ArchiveS3Bucket,
+			c.ArchiveS3AccessKey,
+			c.ArchiveS3SecretKey,
+			c.ArchiveS3Secure,
+		)
+	default:
+		return nil, errors.New("unknown archive store " + string(c.ArchiveStore))
+	}
+}
+
+// archiveBlob stores an ingested blob in the archive, failures are only
+// logged as the blob is already indexed
+func (b *Blob) archiveBlob(ctx context.Context, blob *SourceBlob) {
+	// re-indexing from the archive does not have to write the blobs back
+	if b.archive == nil || b.dep.Config.BlobSource == config.BlobSourceArchive {
+		return
+	}
+	err := b.archive.Put(ctx, blob)
+	if err != nil {
+		log.Error().Err(err).Str("versioned_hash", blob.VersionedHash.Hex()).Msg("failed to archive blob")
+	}
+}
+
+// pruneArchive removes the archived blobs of orphaned blocks from the fork
+// point on, so re-indexing from the archive does not ingest them again.
+// Blobs of blocks that are still canonical are kept.
+func (b *Blob) pruneArchive(ctx context.Context, forkPoint int64) error {
+	if b.archive == nil {
+		return nil
+	}
+	cursor := BlobCursor{BlockNumber: uint64(forkPoint)}
+	var header *types.Header
+	for {
+		blobs, next, err := b.archive.Blobs(ctx, cursor, blobsPerPage)
+		if err != nil {
+			return errors.New("failed to get archived blobs: " + err.Error())
+		}
+		for _, blob := range blobs {
+			// blobs without a block hash can not be told apart
+			if blob.BlockHash == (common.Hash{}) {
+				continue
+			}
+			if header == nil || header.Number.Uint64() != blob.BlockNumber {
+				header, err = b.client.HeaderByNumber(ctx, new(big.Int).SetUint64(blob.BlockNumber))
+				if err != nil && !errors.Is(err, ethereum.NotFound) {
+					return errors.New("failed to get header: " + err.Error())
+				}
+			}
+			// blocks above the new head are orphaned as well
+			if header != nil && header.Hash() == blob.BlockHash {
+				continue
+			}
+			err = b.archive.Remove(ctx, blob)
+			if err != nil {
+				return errors.New("failed to remove archived blob: " + err.Error())
+			}
+			log.Info().Str("versioned_hash", blob.VersionedHash.Hex()).Uint64("block_number", blob.BlockNumber).Msg("removed orphaned blob from archive")
+		}
+		if len(blobs) == 0 || next == cursor {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// archiveSubmittedTx stores the blobs of one of our confirmed transactions,
+// so they are archived even if the indexer is not running
+func (b *Blob) archiveSubmittedTx(ctx context.Context, ptx *pendingTx, receipt *types.Receipt) error {
+	if b.archive == nil {
+		return nil
+	}
+	block, err := b.client.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return errors.New("failed to get block: " + err.Error())
+	}
+	// calldata transactions are archived by the indexer, which knows how
+	// their source numbers them
+	if len(block.Transactions()[receipt.TransactionIndex].BlobHashes()) == 0 {
+		return nil
+	}
+	sidecar, err := buildSidecar(ptx.payloads)
+	if err != nil {
+		return err
+	}
+	// the index of a blob within its block counts the blobs of all
+	// transactions in front of ours
+	offset := 0
+	for _, tx := range block.Transactions()[:receipt.TransactionIndex] {
+		offset += len(tx.BlobHashes())
+	}
+	hashes := sidecar.BlobHashes()
+	for i := range sidecar.Blobs {
+		err = b.archive.Put(ctx, &SourceBlob{
+			BlockNumber:    block.NumberU64(),
+			BlockHash:      block.Hash(),
+			BlockTimestamp: time.Unix(int64(block.Time()), 0).UTC(),
+			Index:          offset + i,
+			VersionedHash:  hashes[i],
+			Commitment:     &sidecar.Commitments[i],
+			Proof:          &sidecar.Proofs[i],
+			Data:           &sidecar.Blobs[i],
+		})
+		if err != nil {
+			return errors.New("failed to archive blob: " + err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// prefix of the blob objects within the bucket
+const s3BlobPrefix = "blobs/"
+
+// S3Archive archives blobs in an S3 compatible bucket such as MinIO, objects
+// use the same JSON encoding and names as the LocalSource files
+type S3Archive struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Archive(endpoint, bucket, accessKey, secretKey string, secure bool) (*S3Archive, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: secure,
+	})
+	if err != nil {
+		return nil, errors.New("failed to create s3 client: " + err.Error())
+	}
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, errors.New("failed to check s3 bucket: " + err.Error())
+	}
+	if !exists {
+		err = client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{})
+		if err != nil {
+			return nil, errors.New("failed to create s3 bucket: " + err.Error())
+		}
+	}
+	return &S3Archive{client: client, bucket: bucket}, nil
+}
+
+func (s *S3Archive) Put(ctx context.Context, blob *SourceBlob) error {
+	data, err := json.Marshal(blob)
+	if err != nil {
+		return errors.New("failed to marshal blob: " + err.Error())
+	}
+	_, err = s.client.PutObject(
+		ctx,
+		s.bucket,
+		s3BlobPrefix+blobFileName(blob.BlockNumber, blob.Index),
+		bytes.NewReader(data),
+		int64(len(data)),
+		minio.PutObjectOptions{ContentType: "application/json"},
+	)
+	if err != nil {
+		return errors.New("failed to put blob: " + err.Error())
+	}
+	return nil
+}
+
+func (s *S3Archive) Remove(ctx context.Context, blob *SourceBlob) error {
+	err := s.client.RemoveObject(ctx, s.bucket, s3BlobPrefix+blobFileName(blob.BlockNumber, blob.Index), minio.RemoveObjectOptions{})
+	if err != nil {
+		return errors.New("failed to remove blob: " + err.Error())
+	}
+	return nil
+}
+
+func (s *S3Archive) Blobs(ctx context.Context, cursor BlobCursor, limit int) ([]*SourceBlob, BlobCursor, error) {
+	// stop the listing once enough blobs were read
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	next := cursor
+	var blobs []*SourceBlob
+	// objects are listed by key and therefore by block number and index,
+	// the prefix of the cursor block sorts in front of all its blobs
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:     s3BlobPrefix,
+		StartAfter: s3BlobPrefix + blockFilePrefix(cursor.BlockNumber),
+		Recursive:  true,
+	})
+	for object := range objects {
+		if object.Err != nil {
+			return nil, cursor, errors.New("failed to list blobs: " + object.Err.Error())
+		}
+		position, ok := parseBlobFileName(path.Base(object.Key))
+		if !ok || !cursor.includes(&SourceBlob{BlockNumber: position.BlockNumber, Index: position.Index}) {
+			continue
+		}
+		if len(blobs) >= limit {
+			break
+		}
+		blob, err := s.get(ctx, object.Key)
+		if err != nil {
+			return nil, cursor, err
+		}
+		blobs = append(blobs, blob)
+		next = cursorAfter(blob)
+	}
+	return blobs, next, nil
+}
+
+func (s *S3Archive) Blob(ctx context.Context, blockNumber uint64, versionedHash common.Hash) (*SourceBlob, error) {
+	var blobs []*SourceBlob
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    s3BlobPrefix + blockFilePrefix(blockNumber),
+		Recursive: true,
+	})
+	for object := range objects {
+		if object.Err != nil {
+			return nil, errors.New("failed to list blobs: " + object.Err.Error())
+		}
+		blob, err := s.get(ctx, object.Key)
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, blob)
+	}
+	return findBlob(blobs, versionedHash)
+}
+
+func (s *S3Archive) get(ctx context.Context, key string) (*SourceBlob, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.New("failed to get blob: " + err.Error())
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, errors.New("failed to read blob: " + err.Error())
+	}
+	var blob SourceBlob
+	err = json.Unmarshal(data, &blob)
+	if err != nil {
+		return nil, errors.New("failed to unmarshal blob " + key + ": " + err.Error())
+	}
+	return &blob, nil
+}
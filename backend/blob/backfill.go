@@ -0,0 +1,142 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"strconv"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// backfill indexes the blocks [from, to) in chunks with a bounded pool of
+// workers. Every chunk is checkpointed in the database once it is complete,
+// so a restarted backfill only processes the chunks that were left over.
+// Chunk boundaries depend on the configured chunk size, changing it between
+// runs processes the range again, which is harmless as ingestion is idempotent.
+func (b *Blob) backfill(ctx context.Context, from, to uint64) error {
+	return runBackfill(ctx, b.queries, from, to, b.dep.Config.BackfillChunkSize, b.dep.Config.BackfillWorkers, b.backfillRange)
+}
+
+// backfillQuerier is the part of the queries the backfill checkpoints through
+type backfillQuerier interface {
+	AddBackfillRange(ctx context.Context, arg dbgen.AddBackfillRangeParams) error
+	GetPendingBackfillRanges(ctx context.Context, arg dbgen.GetPendingBackfillRangesParams) ([]dbgen.MessageBackfillRange, error)
+	CompleteBackfillRange(ctx context.Context, arg dbgen.CompleteBackfillRangeParams) error
+}
+
+// runBackfill splits [from, to) into chunks, hands the pending ones to the
+// workers running ingest and checkpoints every chunk ingest completed
+func runBackfill(ctx context.Context, q backfillQuerier, from, to, chunkSize uint64, workers int, ingest func(context.Context, dbgen.MessageBackfillRange) error) error {
+	for start := from; start < to; start += chunkSize {
+		err := q.AddBackfillRange(ctx, dbgen.AddBackfillRangeParams{
+			FromBlock: int64(start),
+			ToBlock:   int64(min(start+chunkSize, to)),
+		})
+		if err != nil {
+			return errors.New("failed to add backfill range: " + err.Error())
+		}
+	}
+	ranges, err := q.GetPendingBackfillRanges(ctx, dbgen.GetPendingBackfillRangesParams{
+		FromBlock: int64(from),
+		ToBlock:   int64(to),
+	})
+	if err != nil {
+		return errors.New("failed to get pending backfill ranges: " + err.Error())
+	}
+	log.Info().
+		Uint64("from_block", from).
+		Uint64("to_block", to).
+		Int("pending_ranges", len(ranges)).
+		Msg("starting backfill")
+
+	jobs := make(chan dbgen.MessageBackfillRange)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				err := completeRange(ctx, q, r, ingest)
+				if err != nil {
+					// the range stays pending and is retried on the next run
+					log.Error().Err(err).
+						Int64("from_block", r.FromBlock).
+						Int64("to_block", r.ToBlock).
+						Msg("failed to backfill range")
+					mu.Lock()
+					failed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, r := range ranges {
+		select {
+		case jobs <- r:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if failed > 0 {
+		return errors.New(strconv.Itoa(failed) + " backfill ranges failed")
+	}
+	log.Info().Uint64("from_block", from).Uint64("to_block", to).Msg("backfill complete")
+	return nil
+}
+
+// completeRange runs ingest over the range and checkpoints it
+func completeRange(ctx context.Context, q backfillQuerier, r dbgen.MessageBackfillRange, ingest func(context.Context, dbgen.MessageBackfillRange) error) error {
+	err := ingest(ctx, r)
+	if err != nil {
+		return err
+	}
+	err = q.CompleteBackfillRange(ctx, dbgen.CompleteBackfillRangeParams{
+		FromBlock: r.FromBlock,
+		ToBlock:   r.ToBlock,
+	})
+	if err != nil {
+		return errors.New("failed to complete backfill range: " + err.Error())
+	}
+	log.Info().Int64("from_block", r.FromBlock).Int64("to_block", r.ToBlock).Msg("backfilled range")
+	return nil
+}
+
+// backfillRange ingests all blobs of a single range
+func (b *Blob) backfillRange(ctx context.Context, r dbgen.MessageBackfillRange) error {
+	end := uint64(r.ToBlock)
+	cursor := BlobCursor{BlockNumber: uint64(r.FromBlock)}
+	for cursor.BlockNumber < end {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		next, err := b.updatePage(ctx, cursor, end)
+		if err != nil {
+			return err
+		}
+		if next == cursor {
+			// the source has nothing beyond the cursor, which only
+			// completes the range if the chain is past its end
+			head, err := b.client.BlockNumber(ctx)
+			if err != nil {
+				return errors.New("failed to get block number: " + err.Error())
+			}
+			if head < end-1 {
+				return errors.New("backfill range ends after the chain head " + strconv.FormatUint(head, 10))
+			}
+			break
+		}
+		cursor = next
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+)
+
+// fakeBackfillQueries keeps the backfill checkpoints in memory
+type fakeBackfillQueries struct {
+	mu     sync.Mutex
+	ranges []dbgen.MessageBackfillRange
+}
+
+func (q *fakeBackfillQueries) AddBackfillRange(ctx context.Context, arg dbgen.AddBackfillRangeParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, r := range q.ranges {
+		if r.FromBlock == arg.FromBlock && r.ToBlock == arg.ToBlock {
+			return nil
+		}
+	}
+	q.ranges = append(q.ranges, dbgen.MessageBackfillRange{FromBlock: arg.FromBlock, ToBlock: arg.ToBlock})
+	return nil
+}
+
+func (q *fakeBackfillQueries) GetPendingBackfillRanges(ctx context.Context, arg dbgen.GetPendingBackfillRangesParams) ([]dbgen.MessageBackfillRange, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var pending []dbgen.MessageBackfillRange
+	for _, r := range q.ranges {
+		if r.CompletedAt == nil && r.FromBlock >= arg.FromBlock && r.ToBlock <= arg.ToBlock {
+			pending = append(pending, r)
+		}
+	}
+	return pending, nil
+}
+
+func (q *fakeBackfillQueries) CompleteBackfillRange(ctx context.Context, arg dbgen.CompleteBackfillRangeParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, r := range q.ranges {
+		if r.FromBlock == arg.FromBlock && r.ToBlock == arg.ToBlock {
+			q.ranges[i].CompletedAt = ptr(time.Now())
+		}
+	}
+	return nil
+}
+
+// recordingIngest returns an ingest func that records the ranges it got and
+// fails the ones starting at a failing block
+func recordingIngest(failing ...int64) (func(context.Context, dbgen.MessageBackfillRange) error, func() [][2]int64) {
+	var (
+		mu     sync.Mutex
+		ranges [][2]int64
+	)
+	ingest := func(ctx context.Context, r dbgen.MessageBackfillRange) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ranges = append(ranges, [2]int64{r.FromBlock, r.ToBlock})
+		if slices.Contains(failing, r.FromBlock) {
+			return errors.New("source unavailable")
+		}
+		return nil
+	}
+	seen := func() [][2]int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		slices.SortFunc(ranges, func(a, b [2]int64) int { return int(a[0] - b[0]) })
+		return ranges
+	}
+	return ingest, seen
+}
+
+func TestRunBackfill(t *testing.T) {
+	q := &fakeBackfillQueries{}
+
+	// the last chunk is cut at the end of the range
+	ingest, seen := recordingIngest(200)
+	err := runBackfill(context.Background(), q, 100, 350, 100, 3, ingest)
+	if err == nil {
+		t.Fatal("expected the failed range to fail the backfill")
+	}
+	want := [][2]int64{{100, 200}, {200, 300}, {300, 350}}
+	if got := seen(); !slices.Equal(got, want) {
+		t.Fatalf("expected ranges %v, got %v", want, got)
+	}
+
+	// a restart only processes the range left over
+	ingest, seen = recordingIngest()
+	err = runBackfill(context.Background(), q, 100, 350, 100, 3, ingest)
+	if err != nil {
+		t.Fatalf("backfill error: %v", err)
+	}
+	if got := seen(); !slices.Equal(got, [][2]int64{{200, 300}}) {
+		t.Fatalf("expected only the failed range again, got %v", got)
+	}
+
+	// everything is checkpointed now
+	ingest, seen = recordingIngest()
+	err = runBackfill(context.Background(), q, 100, 350, 100, 3, ingest)
+	if err != nil || len(seen()) != 0 {
+		t.Fatalf("expected nothing left to backfill, got %v (%v)", seen(), err)
+	}
+}
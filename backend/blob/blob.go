@@ -6,27 +6,33 @@ import (
 	"math"
 	"math/big"
 	"proto-dankmessaging/backend/dependencies"
+	"proto-dankmessaging/backend/dependencies/config"
 	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/holiman/uint256"
 	"github.com/rs/zerolog/log"
-	"google.golang.org/protobuf/proto"
 )
 
 type Blob struct {
-	dep         *dependencies.Dependencies
-	queries     *dbgen.Queries
-	key         *keystore.Key
-	client      *ethclient.Client
-	blockHeight int64
+	dep     *dependencies.Dependencies
+	queries *dbgen.Queries
+	key     *keystore.Key
+	client  *ethclient.Client
+	source  BlobSource
+	archive BlobArchive
+	da      DABackend
+	fill    *fillPolicy
+	relays  *relayFilter
+	packed  *packCache
 }
 
 func NewBlob(dep *dependencies.Dependencies) (*Blob, error) {
@@ -39,26 +45,55 @@ func NewBlob(dep *dependencies.Dependencies) (*Blob, error) {
 		PrivateKey: privateKey,
 	}
 
-	rpcClient, err := rpc.Dial(dep.Config.RpcUrl)
+	// the local backend runs without a chain
+	var client *ethclient.Client
+	if dep.Config.RpcUrl != "" {
+		rpcClient, err := rpc.Dial(dep.Config.RpcUrl)
+		if err != nil {
+			return nil, errors.New("failed to connect to the Ethereum client: " + err.Error())
+		}
+		client = ethclient.NewClient(rpcClient)
+	}
+
+	source, err := newBlobSource(dep.Config, client, key.Address)
 	if err != nil {
-		return nil, errors.New("failed to connect to the Ethereum client: " + err.Error())
+		return nil, err
+	}
+	archive, err := newBlobArchive(dep.Config)
+	if err != nil {
+		return nil, err
 	}
-	client := ethclient.NewClient(rpcClient)
+
+	da, err := newDABackend(dep.Config, client, key, source)
+	if err != nil {
+		return nil, err
+	}
+
+	// blobs of the relays and the inbox are retried if their download
+	// fails, an unset inbox address leaves the zero address matching nothing
+	chainID := new(big.Int).SetUint64(dep.Config.ChainId)
+	relays := newRelayFilter(chainID, configuredRelays(dep.Config, key.Address), common.HexToAddress(dep.Config.InboxAddress))
 
 	queries := dbgen.New(dep.DB.Pool())
-	blockHeight, err := queries.GetBlobUpdate(context.Background())
+	// the cursor starts at the start block on an empty database
+	_, err = queries.GetBlobUpdate(context.Background())
 	if err != nil {
-		err = queries.SetBlobUpdate(context.Background(), 8698539)
+		err = queries.SetBlobUpdate(context.Background(), int64(dep.Config.StartBlock))
 		if err != nil {
 			return nil, errors.New("failed to set blob update: " + err.Error())
 		}
 	}
 	return &Blob{
-		dep:         dep,
-		queries:     queries,
-		key:         key,
-		client:      client,
-		blockHeight: int64(blockHeight),
+		dep:     dep,
+		queries: queries,
+		key:     key,
+		client:  client,
+		source:  da,
+		archive: archive,
+		da:      da,
+		fill:    newFillPolicy(dep.Config),
+		relays:  relays,
+		packed:  &packCache{},
 	}, nil
 }
 
@@ -67,10 +102,22 @@ var blobMsgMagicBytes = []byte{0x2f, 0x39, 0x4d, 0x21}
 // should keep listening for new blobs and add them to the database
 func (b *Blob) Start(ctx context.Context) error {
 	submitterTicker := time.NewTicker(1 * time.Second)
+	receiptTicker := time.NewTicker(6 * time.Second)
 	var updateTicker *time.Ticker
+	if b.dep.Config.BackfillTo > 0 {
+		go func() {
+			err := b.backfill(ctx, b.dep.Config.BackfillFrom, b.dep.Config.BackfillTo)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to backfill blobs")
+			}
+		}()
+	}
+	if b.dep.Config.BlobUpdate || b.dep.Config.BackfillTo > 0 {
+		go b.retryFailedBlobsLoop(ctx)
+	}
 	if b.dep.Config.BlobUpdate {
 		updateTicker = time.NewTicker(20 * time.Second)
-		b.updateBlob()
+		b.updateBlob(ctx)
 	} else {
 		updateTicker = time.NewTicker(time.Duration(math.MaxInt64))
 	}
@@ -83,8 +130,13 @@ func (b *Blob) Start(ctx context.Context) error {
 			if err != nil {
 				log.Error().Err(err).Msg("failed to generate and submit blob")
 			}
+		case <-receiptTicker.C:
+			err := b.checkPendingTxs(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to check pending blob transactions")
+			}
 		case <-updateTicker.C:
-			err := b.updateBlob()
+			err := b.updateBlob(ctx)
 			if err != nil {
 				log.Error().Err(err).Msg("failed to update blob")
 			}
@@ -93,89 +145,293 @@ func (b *Blob) Start(ctx context.Context) error {
 }
 
 func (b *Blob) generateAndSubmitBlob() error {
-	msgs, err := b.queries.GetBlobSubmissions(context.Background())
+	ctx := context.Background()
+	// spill whatever does not fit into one transaction to follow-up transactions
+	for {
+		sent, err := b.submitNextTx(ctx)
+		if err != nil {
+			return err
+		}
+		if !sent {
+			return nil
+		}
+	}
+}
+
+// submitNextTx claims the queued submissions, packs as many of them as fit
+// into one blob transaction and marks them in flight in the same database
+// transaction before the blob transaction is sent, submissions that do not
+// fit or wait for their blob to fill up stay queued for the next call
+func (b *Blob) submitNextTx(ctx context.Context) (bool, error) {
+	dbTx, err := b.dep.DB.Pool().Begin(ctx)
 	if err != nil {
-		return err
+		return false, errors.New("failed to begin transaction: " + err.Error())
+	}
+	defer dbTx.Rollback(ctx)
+	qtx := b.queries.WithTx(dbTx)
+
+	msgs, err := qtx.ClaimBlobSubmissions(ctx)
+	if err != nil {
+		return false, errors.New("failed to claim blob submissions: " + err.Error())
 	}
 	if len(msgs) == 0 {
-		return nil
+		return false, nil
 	}
-	blob := &BlobContent{
-		Messages: []*Message{},
+	blobs, oversized, err := b.packed.pack(msgs)
+	if err != nil {
+		return false, err
 	}
-	for _, msg := range msgs {
-		blob.Messages = append(blob.Messages, &Message{
-			EphemeralPubkey: msg.Pubkey,
-			SearchIndex:     msg.Index,
-			Message:         msg.Message,
+	for _, msg := range oversized {
+		log.Error().Int32("submission_id", msg.ID).Msg("submission does not fit into a blob")
+		err = qtx.FailBlobSubmission(ctx, dbgen.FailBlobSubmissionParams{
+			ID:    msg.ID,
+			Error: ptr("message does not fit into a blob"),
 		})
+		if err != nil {
+			return false, errors.New("failed to fail blob submission: " + err.Error())
+		}
+		// the message will never be sent, so it must not show up as pending
+		err = qtx.RemovePendingMessages(ctx, &msg.ID)
+		if err != nil {
+			return false, errors.New("failed to remove pending messages: " + err.Error())
+		}
+	}
+	if len(blobs) == 0 {
+		return false, dbTx.Commit(ctx)
 	}
-	blobBytes, err := proto.Marshal(blob)
+	maxBlobs, err := b.da.MaxPayloads(ctx)
 	if err != nil {
-		return err
+		return false, err
+	}
+	count, trigger, err := b.flushBlobs(ctx, blobs, maxBlobs)
+	if err != nil {
+		return false, err
+	}
+	if count == 0 {
+		// the claimed submissions keep waiting for the blob to fill up
+		return false, dbTx.Commit(ctx)
+	}
+	txBlobs := blobs[:count]
+	payloads := make([][]byte, len(txBlobs))
+	for i, blob := range txBlobs {
+		payloads[i] = blob.payload
+	}
+
+	if b.dep.Config.DABackend == config.DABackendLocal {
+		err = b.publishLocal(ctx, qtx, txBlobs, payloads, trigger)
+		if err != nil {
+			return false, err
+		}
+		return true, dbTx.Commit(ctx)
+	}
+
+	log.Info().Int("blob_count", len(payloads)).Str("trigger", string(trigger)).Msg("submitting blobs to the chain")
+	ptx, signedTx, cost, err := b.prepareBlobTx(ctx, qtx, payloads)
+	if err != nil {
+		return false, err
+	}
+	// payloads left out by the carrier stay queued for the next transaction
+	txBlobs = txBlobs[:len(ptx.payloads)]
+	params, err := ptx.addParams()
+	if err != nil {
+		return false, err
+	}
+	err = qtx.AddBlobTx(ctx, params)
+	if err != nil {
+		return false, errors.New("failed to add blob tx: " + err.Error())
 	}
-	blobBytes = append(blobMsgMagicBytes, blobBytes...)
-	log.Info().Bytes("blob", blobBytes).Msg("submitting blob to the chain")
-	err = b.submitBlob(context.Background(), blobBytes)
+	nonce := int64(ptx.nonce)
+	blobHashes := ptx.sidecar.BlobHashes()
+	for i, blob := range txBlobs {
+		ids := make([]int32, len(blob.submissions))
+		for j, msg := range blob.submissions {
+			ids[j] = msg.ID
+		}
+		err = qtx.MarkBlobSubmissionsInFlight(ctx, dbgen.MarkBlobSubmissionsInFlightParams{
+			Nonce:         &nonce,
+			TxHash:        signedTx.Hash().Bytes(),
+			VersionedHash: blobHashes[i].Bytes(),
+			Ids:           ids,
+		})
+		if err != nil {
+			return false, errors.New("failed to mark blob submissions in flight: " + err.Error())
+		}
+		err = qtx.MarkMessagesSubmitted(ctx, ids)
+		if err != nil {
+			return false, errors.New("failed to mark messages submitted: " + err.Error())
+		}
+		err = recordBatch(ctx, qtx, blob, blobHashes[i].Bytes(), &nonce, trigger)
+		if err != nil {
+			return false, err
+		}
+	}
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return false, errors.New("failed to commit transaction: " + err.Error())
+	}
+
+	// the transaction is not requeued on a send error, resending its
+	// messages under another nonce would post them twice if the node took
+	// it after all, checkPendingTxs replaces or requeues it instead
+	err = b.sendBlobTx(ctx, signedTx, cost)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// publishLocal writes the payloads through a backend without transactions,
+// their submissions are confirmed right away as there is nothing to track
+func (b *Blob) publishLocal(ctx context.Context, qtx *dbgen.Queries, blobs []*packedBlob, payloads [][]byte, trigger dbgen.MessageFlushTrigger) error {
+	sidecar, err := buildSidecar(payloads)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to submit blob")
 		return err
 	}
-	for _, msg := range msgs {
-		err = b.queries.RemoveBlobSubmission(context.Background(), msg.ID)
+	_, err = b.da.Submit(&pendingTx{payloads: payloads, sidecar: sidecar, carrier: dbgen.MessageTxCarrierBlob}, nil)
+	if err != nil {
+		return errors.New("failed to publish blobs: " + err.Error())
+	}
+	blobHashes := sidecar.BlobHashes()
+	for i, blob := range blobs {
+		ids := make([]int32, len(blob.submissions))
+		for j, msg := range blob.submissions {
+			ids[j] = msg.ID
+		}
+		err = qtx.PublishBlobSubmissions(ctx, dbgen.PublishBlobSubmissionsParams{
+			VersionedHash: blobHashes[i].Bytes(),
+			Ids:           ids,
+		})
+		if err != nil {
+			return errors.New("failed to publish blob submissions: " + err.Error())
+		}
+		err = qtx.MarkMessagesSubmitted(ctx, ids)
+		if err != nil {
+			return errors.New("failed to mark messages submitted: " + err.Error())
+		}
+		err = recordBatch(ctx, qtx, blob, blobHashes[i].Bytes(), nil, trigger)
 		if err != nil {
 			return err
 		}
 	}
+	log.Info().Int("blob_count", len(payloads)).Str("trigger", string(trigger)).Msg("published blobs locally")
 	return nil
 }
 
-func (b *Blob) submitBlob(ctx context.Context, blobBytes []byte) error {
-	signer := types.NewPragueSigner(big.NewInt(int64(b.dep.Config.ChainId)))
+// prepareBlobTx prices and signs a new transaction carrying the payloads,
+// it may take only the first few of them depending on the carrier
+func (b *Blob) prepareBlobTx(ctx context.Context, qtx *dbgen.Queries, payloads [][]byte) (*pendingTx, *types.Transaction, *big.Int, error) {
 	nonce, err := b.client.PendingNonceAt(ctx, b.key.Address)
 	if err != nil {
-		return errors.New("failed to get nonce: " + err.Error())
+		return nil, nil, nil, errors.New("failed to get nonce: " + err.Error())
 	}
-	blob, err := EncodeDataToBlob(blobBytes)
+	// a dropped transaction of ours may still hold a higher nonce
+	next, err := qtx.GetNextBlobTxNonce(ctx)
 	if err != nil {
-		return errors.New("failed to encode data to blob: " + err.Error())
+		return nil, nil, nil, errors.New("failed to get next blob tx nonce: " + err.Error())
 	}
-	blobCommitment, err := kzg4844.BlobToCommitment(blob)
+	nonce = max(nonce, uint64(next))
+	market, err := b.getMarketFees(ctx)
 	if err != nil {
-		return errors.New("failed to compute blob commitment: " + err.Error())
+		return nil, nil, nil, err
 	}
-	blobProof, err := kzg4844.ComputeBlobProof(blob, blobCommitment)
+	fees := calcTxFees(b.dep.Config, market.BaseFee, market.BlobBaseFee, market.Tip)
+	// the mempool holds one kind of transaction per sender, so a new one
+	// has to use the carrier of those still pending
+	carriers, err := qtx.GetBlobTxCarriers(ctx)
 	if err != nil {
-		return errors.New("failed to compute blob proof: " + err.Error())
+		return nil, nil, nil, errors.New("failed to get blob tx carriers: " + err.Error())
+	}
+	var pending dbgen.MessageTxCarrier
+	if len(carriers) > 0 {
+		pending = carriers[0]
 	}
-	sidecar := types.BlobTxSidecar{
-		Blobs:       []kzg4844.Blob{*blob},
-		Commitments: []kzg4844.Commitment{blobCommitment},
-		Proofs:      []kzg4844.Proof{blobProof},
+	carrier, count := b.da.Carrier(payloads, market, fees, pending)
+	payloads = payloads[:count]
+	sidecar, err := buildSidecar(payloads)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	ptx := &pendingTx{
+		nonce:    nonce,
+		payloads: payloads,
+		sidecar:  sidecar,
+		carrier:  carrier,
+	}
+	cost, err := b.da.MaxCost(ctx, ptx, fees)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	err = b.checkSpendingCaps(ctx, cost, cost)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	signedTx, err := b.signBlobTx(ptx, fees)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ptx, signedTx, cost, nil
+}
 
-	tx := types.NewTx(&types.BlobTx{
-		ChainID:    uint256.NewInt(b.dep.Config.ChainId),
-		Nonce:      nonce,
-		GasTipCap:  uint256.NewInt(10_000_000_000),
-		GasFeeCap:  uint256.NewInt(10_000_000_000),
-		Gas:        21000,
-		To:         common.HexToAddress("0x0000000000000000000000000000000000000000"),
-		Value:      uint256.NewInt(0),
-		Data:       nil,
-		BlobFeeCap: uint256.NewInt(3e10),
-		BlobHashes: sidecar.BlobHashes(),
-		Sidecar:    &sidecar,
-	})
+func buildSidecar(payloads [][]byte) (*types.BlobTxSidecar, error) {
+	sidecar := &types.BlobTxSidecar{}
+	for _, blobBytes := range payloads {
+		blob, err := EncodeDataToBlob(blobBytes)
+		if err != nil {
+			return nil, errors.New("failed to encode data to blob: " + err.Error())
+		}
+		blobCommitment, err := kzg4844.BlobToCommitment(blob)
+		if err != nil {
+			return nil, errors.New("failed to compute blob commitment: " + err.Error())
+		}
+		blobProof, err := kzg4844.ComputeBlobProof(blob, blobCommitment)
+		if err != nil {
+			return nil, errors.New("failed to compute blob proof: " + err.Error())
+		}
+		sidecar.Blobs = append(sidecar.Blobs, *blob)
+		sidecar.Commitments = append(sidecar.Commitments, blobCommitment)
+		sidecar.Proofs = append(sidecar.Proofs, blobProof)
+	}
+	return sidecar, nil
+}
 
-	signedTx, err := types.SignTx(tx, signer, b.key.PrivateKey)
+// signBlobTx signs the transaction publishing the payloads of ptx with the
+// given fees and records the new version on ptx
+func (b *Blob) signBlobTx(ptx *pendingTx, fees *txFees) (*types.Transaction, error) {
+	signedTx, err := b.da.Submit(ptx, fees)
 	if err != nil {
-		return errors.New("failed to sign transaction: " + err.Error())
+		return nil, err
 	}
-	if err = b.client.SendTransaction(ctx, signedTx); err != nil {
-		return errors.New("failed to send transaction: " + err.Error())
+	ptx.fees = fees
+	ptx.hashes = append(ptx.hashes, signedTx.Hash())
+	ptx.sentAt = time.Now()
+	return signedTx, nil
+}
+
+// sendBlobTx sends a signed blob transaction and books its additional worst
+// case cost against the daily cap. A failed send may still have reached the
+// node, so the cost is booked either way and the transaction stays tracked
+// until checkPendingTxs settles it by its nonce and receipt.
+func (b *Blob) sendBlobTx(ctx context.Context, signedTx *types.Transaction, additional *big.Int) error {
+	sendErr := b.client.SendTransaction(ctx, signedTx)
+	if sendErr != nil && strings.Contains(sendErr.Error(), txpool.ErrAlreadyKnown.Error()) {
+		sendErr = nil
 	}
 	txHash := signedTx.Hash().Hex()
-	log.Info().Str("tx_hash", txHash).Msg("submitted blob to the chain")
+	err := b.queries.AddBlobFee(ctx, dbgen.AddBlobFeeParams{
+		TxHash:     signedTx.Hash().Bytes(),
+		Fee:        additional.Int64(),
+		SubmitTime: time.Now(),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("tx_hash", txHash).Msg("failed to record blob fee")
+	}
+	if sendErr != nil {
+		return errors.New("failed to send transaction: " + sendErr.Error())
+	}
+	log.Info().Str("tx_hash", txHash).Uint64("nonce", signedTx.Nonce()).Msg("submitted blob to the chain")
 	return nil
 }
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,50 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"proto-dankmessaging/backend/dependencies/config"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DABackend is the data availability layer the relay publishes its payloads
+// to, the indexer fetches them back through its BlobSource methods
+type DABackend interface {
+	BlobSource
+	// MaxPayloads returns how many payloads fit into one submission
+	MaxPayloads(ctx context.Context) (int, error)
+	// Carrier picks how the next transaction carries the payloads and
+	// returns how many of them it takes, pending is the carrier of the
+	// transactions still pending or empty if there are none
+	Carrier(payloads [][]byte, market *marketFees, fees *txFees, pending dbgen.MessageTxCarrier) (dbgen.MessageTxCarrier, int)
+	// MaxCost returns the most publishing the payloads of ptx can cost
+	// under the fees
+	MaxCost(ctx context.Context, ptx *pendingTx, fees *txFees) (*big.Int, error)
+	// Submit publishes the payloads of ptx. Backends publishing through
+	// transactions return one signed with the nonce of ptx and the fees
+	// without sending it, the submitter sends it and tracks it until it is
+	// confirmed. Backends without transactions write the payloads right away
+	// and return nil.
+	Submit(ptx *pendingTx, fees *txFees) (*types.Transaction, error)
+}
+
+// newDABackend returns the configured backend, the l1_blob backend fetches
+// through the configured blob source
+func newDABackend(c *config.Config, client *ethclient.Client, key *keystore.Key, source BlobSource) (DABackend, error) {
+	chainID := new(big.Int).SetUint64(c.ChainId)
+	switch c.DABackend {
+	case config.DABackendL1Blob:
+		return NewL1BlobDA(c, source, client, key)
+	case config.DABackendCalldata:
+		return NewCalldataDA(client, key, chainID, configuredRelays(c, key.Address)), nil
+	case config.DABackendLocal:
+		return NewLocalDA(c.DADir, c.StartBlock), nil
+	default:
+		return nil, errors.New("unknown da backend " + string(c.DABackend))
+	}
+}
@@ -0,0 +1,224 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rs/zerolog/log"
+)
+
+// CalldataDA publishes every payload as the calldata of a plain transaction
+// on the configured chain, e.g. an L2 such as World Chain. Fetched payloads
+// are encoded into blobs, so they keep the versioned hash the submitter
+// recorded and pass through the indexer like any other blob.
+type CalldataDA struct {
+	client  *ethclient.Client
+	key     *keystore.Key
+	chainID *big.Int
+	filter  *relayFilter
+}
+
+func NewCalldataDA(client *ethclient.Client, key *keystore.Key, chainID *big.Int, relays []common.Address) *CalldataDA {
+	return &CalldataDA{
+		client:  client,
+		key:     key,
+		chainID: chainID,
+		filter:  newRelayFilter(chainID, relays, common.Address{}),
+	}
+}
+
+// MaxPayloads is one, a payload almost fills the transaction size limit of
+// the mempool on its own
+func (d *CalldataDA) MaxPayloads(ctx context.Context) (int, error) {
+	return 1, nil
+}
+
+func (d *CalldataDA) Carrier(payloads [][]byte, market *marketFees, fees *txFees, pending dbgen.MessageTxCarrier) (dbgen.MessageTxCarrier, int) {
+	return dbgen.MessageTxCarrierCalldata, 1
+}
+
+// MaxCost adds the L1 data fee OP-stack chains charge on top of the gas, the
+// transaction does not cap it so the current one is taken
+func (d *CalldataDA) MaxCost(ctx context.Context, ptx *pendingTx, fees *txFees) (*big.Int, error) {
+	cost := d.gasCost(ptx.payloads, fees)
+	for _, payload := range ptx.payloads {
+		l1Fee, err := d.l1Fee(ctx, d.tx(ptx.nonce, payload, fees))
+		if err != nil {
+			return nil, err
+		}
+		cost.Add(cost, l1Fee)
+	}
+	return cost, nil
+}
+
+// gasCost returns the most the gas of the payloads can cost under the fees
+func (d *CalldataDA) gasCost(payloads [][]byte, fees *txFees) *big.Int {
+	cost := new(big.Int)
+	for _, payload := range payloads {
+		cost.Add(cost, fees.maxCost(calldataGas(payload), 0))
+	}
+	return cost
+}
+
+// predeployed GasPriceOracle of OP-stack chains
+var gasPriceOracle = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+// l1Fee returns the L1 data fee the GasPriceOracle charges for the unsigned
+// transaction, it is zero on chains without the oracle
+func (d *CalldataDA) l1Fee(ctx context.Context, tx *types.Transaction) (*big.Int, error) {
+	txData, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, errors.New("failed to encode transaction: " + err.Error())
+	}
+	bytesType, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, errors.New("failed to create abi type: " + err.Error())
+	}
+	args, err := abi.Arguments{{Type: bytesType}}.Pack(txData)
+	if err != nil {
+		return nil, errors.New("failed to pack l1 fee call: " + err.Error())
+	}
+	data := append(crypto.Keccak256([]byte("getL1Fee(bytes)"))[:4], args...)
+	result, err := d.client.CallContract(ctx, ethereum.CallMsg{To: &gasPriceOracle, Data: data}, nil)
+	if err != nil {
+		return nil, errors.New("failed to get l1 fee: " + err.Error())
+	}
+	// calls to an address without code return nothing
+	return new(big.Int).SetBytes(result), nil
+}
+
+// tx returns the unsigned transaction carrying the payload
+func (d *CalldataDA) tx(nonce uint64, payload []byte, fees *txFees) *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   d.chainID,
+		Nonce:     nonce,
+		GasTipCap: fees.GasTipCap,
+		GasFeeCap: fees.GasFeeCap,
+		Gas:       calldataGas(payload),
+		To:        &common.Address{},
+		Value:     new(big.Int),
+		Data:      payload,
+	})
+}
+
+func (d *CalldataDA) Submit(ptx *pendingTx, fees *txFees) (*types.Transaction, error) {
+	if len(ptx.payloads) != 1 {
+		return nil, errors.New("calldata transactions carry a single payload")
+	}
+	signer := types.NewPragueSigner(d.chainID)
+	signedTx, err := types.SignTx(d.tx(ptx.nonce, ptx.payloads[0], fees), signer, d.key.PrivateKey)
+	if err != nil {
+		return nil, errors.New("failed to sign transaction: " + err.Error())
+	}
+	return signedTx, nil
+}
+
+func (d *CalldataDA) Blobs(ctx context.Context, cursor BlobCursor, limit int) ([]*SourceBlob, BlobCursor, error) {
+	head, err := d.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, cursor, errors.New("failed to get block number: " + err.Error())
+	}
+	var blobs []*SourceBlob
+	number := cursor.BlockNumber
+	for ; number <= head && number < cursor.BlockNumber+beaconBlocksPerCall && len(blobs) < limit; number++ {
+		blockBlobs, err := d.blockBlobs(ctx, number)
+		if err != nil {
+			return nil, cursor, err
+		}
+		for _, blob := range blockBlobs {
+			if cursor.includes(blob) {
+				blobs = append(blobs, blob)
+			}
+		}
+	}
+	if number == cursor.BlockNumber {
+		return blobs, cursor, nil
+	}
+	return blobs, BlobCursor{BlockNumber: number}, nil
+}
+
+func (d *CalldataDA) Blob(ctx context.Context, blockNumber uint64, versionedHash common.Hash) (*SourceBlob, error) {
+	blobs, err := d.blockBlobs(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return findBlob(blobs, versionedHash)
+}
+
+// calldataIndexOffset puts calldata payloads behind the blobs of their
+// block, their index is the offset plus the position of their transaction
+const calldataIndexOffset = 1 << 16
+
+// blockBlobs returns the payloads a relay published in the block
+func (d *CalldataDA) blockBlobs(ctx context.Context, number uint64) ([]*SourceBlob, error) {
+	block, err := d.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, errors.New("failed to get block: " + err.Error())
+	}
+	return calldataBlobs(block, d.filter), nil
+}
+
+// calldataBlobs returns the payloads the filtered senders published as
+// calldata in the block
+func calldataBlobs(block *types.Block, filter *relayFilter) []*SourceBlob {
+	var blobs []*SourceBlob
+	for i, tx := range block.Transactions() {
+		if !bytes.HasPrefix(tx.Data(), blobMsgMagicBytes) || !filter.matches(tx) {
+			continue
+		}
+		blob, err := calldataBlob(block, calldataIndexOffset+i, tx)
+		if err != nil {
+			// payloads of the relay always fit into a blob
+			log.Warn().Err(err).Str("tx_hash", tx.Hash().Hex()).Msg("failed to encode calldata payload")
+			continue
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs
+}
+
+// calldataBlob encodes the payload of a calldata transaction into the blob
+// the submitter derived its versioned hash from
+func calldataBlob(block *types.Block, index int, tx *types.Transaction) (*SourceBlob, error) {
+	data, err := EncodeDataToBlob(tx.Data())
+	if err != nil {
+		return nil, errors.New("failed to encode data to blob: " + err.Error())
+	}
+	commitment, err := kzg4844.BlobToCommitment(data)
+	if err != nil {
+		return nil, errors.New("failed to compute blob commitment: " + err.Error())
+	}
+	txHash := tx.Hash()
+	return &SourceBlob{
+		BlockNumber:    block.NumberU64(),
+		BlockHash:      block.Hash(),
+		BlockTimestamp: time.Unix(int64(block.Time()), 0).UTC(),
+		Index:          index,
+		VersionedHash:  kzg4844.CalcBlobHashV1(sha256.New(), &commitment),
+		Commitment:     &commitment,
+		Data:           data,
+		TxHash:         &txHash,
+	}, nil
+}
+
+// calldataGas returns the gas limit of a transaction carrying the payload,
+// data heavy transactions pay the EIP-7623 calldata floor
+func calldataGas(payload []byte) uint64 {
+	gas, _ := core.IntrinsicGas(payload, nil, nil, false, true, true, true)
+	floor, _ := core.FloorDataGas(payload)
+	return max(gas, floor)
+}
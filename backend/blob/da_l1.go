@@ -0,0 +1,150 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"proto-dankmessaging/backend/blob/inbox"
+	"proto-dankmessaging/backend/dependencies/config"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/holiman/uint256"
+	"github.com/rs/zerolog/log"
+)
+
+// L1BlobDA publishes payloads as EIP-4844 blobs and fetches them through a
+// blob source
+type L1BlobDA struct {
+	BlobSource
+	client  *ethclient.Client
+	key     *keystore.Key
+	chainID uint64
+	// to and inboxCall are the inbox and the calldata announcing the blobs,
+	// blob transactions go to the zero address without calldata otherwise
+	to        common.Address
+	inboxCall []byte
+	// calldata carries payloads while blob fees spike, fallbackFee is the
+	// blob base fee above which it competes with blobs and nil if the
+	// fallback is disabled. The execution source indexes the calldata.
+	calldata    *CalldataDA
+	fallbackFee *big.Int
+}
+
+func NewL1BlobDA(c *config.Config, source BlobSource, client *ethclient.Client, key *keystore.Key) (*L1BlobDA, error) {
+	da := &L1BlobDA{
+		BlobSource: source,
+		client:     client,
+		key:        key,
+		chainID:    c.ChainId,
+		calldata:   NewCalldataDA(client, key, new(big.Int).SetUint64(c.ChainId), configuredRelays(c, key.Address)),
+	}
+	if c.CalldataFallbackFee > 0 {
+		da.fallbackFee = new(big.Int).SetUint64(c.CalldataFallbackFee)
+	}
+	if c.UseInbox {
+		inboxCall, err := announceCall()
+		if err != nil {
+			return nil, err
+		}
+		da.to = common.HexToAddress(c.InboxAddress)
+		da.inboxCall = inboxCall
+	}
+	return da, nil
+}
+
+// MaxPayloads returns how many blobs a single transaction may carry on the
+// configured chain under the currently active fork
+func (d *L1BlobDA) MaxPayloads(ctx context.Context) (int, error) {
+	cfg, ok := chainConfigs[d.chainID]
+	if !ok {
+		return maxBlobsPerTx, nil
+	}
+	head, err := d.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, errors.New("failed to get latest header: " + err.Error())
+	}
+	maxBlobs := eip4844.MaxBlobsPerBlock(cfg, head.Time)
+	if maxBlobs == 0 {
+		return 0, errors.New("blobs are not enabled on the configured chain")
+	}
+	return min(maxBlobs, maxBlobsPerTx), nil
+}
+
+// Carrier posts blobs unless the blob base fee is above the fallback fee and
+// calldata is cheaper, a single sender may only have one kind of
+// transaction in the mempool so pending transactions decide on their own
+func (d *L1BlobDA) Carrier(payloads [][]byte, market *marketFees, fees *txFees, pending dbgen.MessageTxCarrier) (dbgen.MessageTxCarrier, int) {
+	switch {
+	case pending == dbgen.MessageTxCarrierCalldata:
+		return d.calldata.Carrier(payloads, market, fees, pending)
+	case pending == dbgen.MessageTxCarrierBlob || d.fallbackFee == nil || market.BlobBaseFee.Cmp(d.fallbackFee) <= 0:
+		return dbgen.MessageTxCarrierBlob, len(payloads)
+	}
+	// calldata on L1 pays no data fee on top of its gas
+	blobCost := fees.maxCost(d.gas(), len(payloads))
+	calldataCost := d.calldata.gasCost(payloads, fees)
+	if calldataCost.Cmp(blobCost) >= 0 {
+		return dbgen.MessageTxCarrierBlob, len(payloads)
+	}
+	log.Info().Str("blob_base_fee", market.BlobBaseFee.String()).Str("blob_cost", blobCost.String()).Str("calldata_cost", calldataCost.String()).Msg("blob fees spiked, posting calldata")
+	return d.calldata.Carrier(payloads, market, fees, pending)
+}
+
+func (d *L1BlobDA) MaxCost(ctx context.Context, ptx *pendingTx, fees *txFees) (*big.Int, error) {
+	if ptx.carrier == dbgen.MessageTxCarrierCalldata {
+		return d.calldata.gasCost(ptx.payloads, fees), nil
+	}
+	return fees.maxCost(d.gas(), len(ptx.payloads)), nil
+}
+
+func (d *L1BlobDA) Submit(ptx *pendingTx, fees *txFees) (*types.Transaction, error) {
+	if ptx.carrier == dbgen.MessageTxCarrierCalldata {
+		return d.calldata.Submit(ptx, fees)
+	}
+	signer := types.NewPragueSigner(new(big.Int).SetUint64(d.chainID))
+	tx := types.NewTx(&types.BlobTx{
+		ChainID:    uint256.NewInt(d.chainID),
+		Nonce:      ptx.nonce,
+		GasTipCap:  uint256.MustFromBig(fees.GasTipCap),
+		GasFeeCap:  uint256.MustFromBig(fees.GasFeeCap),
+		Gas:        d.gas(),
+		To:         d.to,
+		Value:      uint256.NewInt(0),
+		Data:       d.inboxCall,
+		BlobFeeCap: uint256.MustFromBig(fees.BlobFeeCap),
+		BlobHashes: ptx.sidecar.BlobHashes(),
+		Sidecar:    ptx.sidecar,
+	})
+	signedTx, err := types.SignTx(tx, signer, d.key.PrivateKey)
+	if err != nil {
+		return nil, errors.New("failed to sign transaction: " + err.Error())
+	}
+	return signedTx, nil
+}
+
+// gas returns the gas limit of the blob transactions
+func (d *L1BlobDA) gas() uint64 {
+	if d.inboxCall != nil {
+		return inboxTxGas
+	}
+	return blobTxGas
+}
+
+// announceCall returns the calldata of the inbox call announcing the blobs
+// of a transaction with the current payload version
+func announceCall() ([]byte, error) {
+	inboxAbi, err := inbox.BlobInboxMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.New("failed to parse inbox abi: " + err.Error())
+	}
+	data, err := inboxAbi.Pack("announce", uint8(payloadVersion))
+	if err != nil {
+		return nil, errors.New("failed to pack inbox call: " + err.Error())
+	}
+	return data, nil
+}
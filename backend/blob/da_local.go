@@ -0,0 +1,83 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LocalDA publishes payloads as blob files in a directory without touching
+// any chain, every submission becomes a block of its own after the last one
+// in the directory. Blocks are numbered from the start block, so the indexer
+// finds them from its initial cursor.
+type LocalDA struct {
+	*LocalSource
+	startBlock uint64
+}
+
+func NewLocalDA(dir string, startBlock uint64) *LocalDA {
+	return &LocalDA{LocalSource: NewLocalSource(dir), startBlock: startBlock}
+}
+
+func (d *LocalDA) MaxPayloads(ctx context.Context) (int, error) {
+	return maxBlobsPerTx, nil
+}
+
+func (d *LocalDA) Carrier(payloads [][]byte, market *marketFees, fees *txFees, pending dbgen.MessageTxCarrier) (dbgen.MessageTxCarrier, int) {
+	return dbgen.MessageTxCarrierBlob, len(payloads)
+}
+
+// MaxCost is zero, publishing to files is free
+func (d *LocalDA) MaxCost(ctx context.Context, ptx *pendingTx, fees *txFees) (*big.Int, error) {
+	return new(big.Int), nil
+}
+
+func (d *LocalDA) Submit(ptx *pendingTx, fees *txFees) (*types.Transaction, error) {
+	last, err := d.lastBlock()
+	if err != nil {
+		return nil, err
+	}
+	block := max(last+1, d.startBlock)
+	now := time.Now().UTC()
+	hashes := ptx.sidecar.BlobHashes()
+	for i := range ptx.sidecar.Blobs {
+		err = WriteLocalBlob(d.dir, &SourceBlob{
+			BlockNumber:    block,
+			BlockTimestamp: now,
+			Index:          i,
+			VersionedHash:  hashes[i],
+			Commitment:     &ptx.sidecar.Commitments[i],
+			Proof:          &ptx.sidecar.Proofs[i],
+			Data:           &ptx.sidecar.Blobs[i],
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// lastBlock returns the highest block number in the directory, zero if it
+// holds no blobs yet
+func (d *LocalDA) lastBlock() (uint64, error) {
+	entries, err := os.ReadDir(d.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.New("failed to read blob directory: " + err.Error())
+	}
+	var last uint64
+	for _, entry := range entries {
+		position, ok := parseBlobFileName(entry.Name())
+		if ok {
+			last = max(last, position.BlockNumber)
+		}
+	}
+	return last, nil
+}
@@ -0,0 +1,137 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestLocalDA(t *testing.T) {
+	da := NewLocalDA(t.TempDir(), 100)
+	payloads := [][]byte{
+		append(bytes.Clone(blobMsgMagicBytes), 0x01),
+		append(bytes.Clone(blobMsgMagicBytes), 0x02),
+	}
+	for range 2 {
+		sidecar, err := buildSidecar(payloads)
+		if err != nil {
+			t.Fatalf("sidecar error: %v", err)
+		}
+		tx, err := da.Submit(&pendingTx{payloads: payloads, sidecar: sidecar}, nil)
+		if err != nil || tx != nil {
+			t.Fatalf("expected no transaction, got %v and %v", tx, err)
+		}
+	}
+
+	// every submission is a block of its own, counting from the start block
+	blobs, next, err := da.Blobs(context.Background(), BlobCursor{BlockNumber: 100}, 10)
+	if err != nil {
+		t.Fatalf("blobs error: %v", err)
+	}
+	if len(blobs) != 4 || next != (BlobCursor{BlockNumber: 101, Index: 2}) {
+		t.Fatalf("expected 4 blobs and cursor 101/2, got %d and %v", len(blobs), next)
+	}
+	for _, blob := range blobs {
+		if err := blob.Verify(); err != nil {
+			t.Errorf("blob %d/%d: %v", blob.BlockNumber, blob.Index, err)
+		}
+		data, err := DecodeBlobToData(blob.Data)
+		if err != nil || !bytes.Equal(data, payloads[blob.Index]) {
+			t.Errorf("blob %d/%d: expected payload %x, got %x (%v)", blob.BlockNumber, blob.Index, payloads[blob.Index], data, err)
+		}
+	}
+}
+
+func TestCalldataBlob(t *testing.T) {
+	payload := append(bytes.Clone(blobMsgMagicBytes), bytes.Repeat([]byte{0x42}, 1000)...)
+	sidecar, err := buildSidecar([][]byte{payload})
+	if err != nil {
+		t.Fatalf("sidecar error: %v", err)
+	}
+
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(480)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID: chainID,
+		To:      &common.Address{},
+		Gas:     calldataGas(payload),
+		Data:    payload,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(7)}).WithBody(types.Body{Transactions: []*types.Transaction{tx}})
+
+	// the indexer has to arrive at the versioned hash the submitter recorded
+	blob, err := calldataBlob(block, 0, tx)
+	if err != nil {
+		t.Fatalf("calldata blob error: %v", err)
+	}
+	if blob.VersionedHash != sidecar.BlobHashes()[0] {
+		t.Errorf("expected versioned hash %s, got %s", sidecar.BlobHashes()[0].Hex(), blob.VersionedHash.Hex())
+	}
+	if blob.TxHash == nil || *blob.TxHash != tx.Hash() {
+		t.Errorf("expected tx hash %s, got %v", tx.Hash().Hex(), blob.TxHash)
+	}
+	if err := blob.Verify(); err != nil {
+		t.Errorf("verify error: %v", err)
+	}
+	if gas := calldataGas(payload); gas != 21000+10*uint64(4*len(payload)) {
+		t.Errorf("expected the calldata floor, got %d gas", gas)
+	}
+
+	// only payloads of the relays are picked up, behind the blobs of the block
+	relays := newRelayFilter(chainID, []common.Address{crypto.PubkeyToAddress(key.PublicKey)}, common.Address{})
+	if blobs := calldataBlobs(block, relays); len(blobs) != 1 || blobs[0].Index != calldataIndexOffset {
+		t.Errorf("expected the relay payload at index %d, got %d payloads", calldataIndexOffset, len(blobs))
+	}
+	if blobs := calldataBlobs(block, newRelayFilter(chainID, nil, common.Address{})); len(blobs) != 0 {
+		t.Errorf("expected no payloads of foreign senders, got %d", len(blobs))
+	}
+}
+
+func TestL1BlobDACarrier(t *testing.T) {
+	da := &L1BlobDA{
+		calldata:    NewCalldataDA(nil, nil, big.NewInt(1), nil),
+		fallbackFee: big.NewInt(10),
+	}
+	payload := append(bytes.Clone(blobMsgMagicBytes), bytes.Repeat([]byte{0x42}, 1000)...)
+	payloads := [][]byte{payload, payload}
+	gwei := big.NewInt(1_000_000_000)
+	tests := []struct {
+		name        string
+		blobBaseFee *big.Int
+		blobFeeCap  *big.Int
+		pending     dbgen.MessageTxCarrier
+		disabled    bool
+		carrier     dbgen.MessageTxCarrier
+		count       int
+	}{
+		{"calm", big.NewInt(5), gwei, "", false, dbgen.MessageTxCarrierBlob, 2},
+		{"spike", gwei, gwei, "", false, dbgen.MessageTxCarrierCalldata, 1},
+		{"spike with blobs cheaper", big.NewInt(100), big.NewInt(100), "", false, dbgen.MessageTxCarrierBlob, 2},
+		{"spike with blobs pending", gwei, gwei, dbgen.MessageTxCarrierBlob, false, dbgen.MessageTxCarrierBlob, 2},
+		{"calm with calldata pending", big.NewInt(5), gwei, dbgen.MessageTxCarrierCalldata, false, dbgen.MessageTxCarrierCalldata, 1},
+		{"spike without fallback", gwei, gwei, "", true, dbgen.MessageTxCarrierBlob, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := *da
+			if tt.disabled {
+				d.fallbackFee = nil
+			}
+			market := &marketFees{BlobBaseFee: tt.blobBaseFee}
+			fees := &txFees{GasTipCap: gwei, GasFeeCap: gwei, BlobFeeCap: tt.blobFeeCap}
+			carrier, count := d.Carrier(payloads, market, fees, tt.pending)
+			if carrier != tt.carrier || count != tt.count {
+				t.Errorf("expected %s with %d payloads, got %s with %d", tt.carrier, tt.count, carrier, count)
+			}
+		})
+	}
+}
@@ -2,57 +2,113 @@ package blob
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 )
 
-func EncodeDataToBlob(data []byte) (*kzg4844.Blob, error) {
-	const (
-		BlobSize         = 131072 // 4096 * 32 bytes
-		FieldElementSize = 32
-		// Use 31 bytes per field element to ensure canonical form
-		UsableBytes = 31
-	)
-
-	var blob kzg4844.Blob
+// The blob codec follows the encoding used by rollups such as the OP stack:
+// every round of 4 field elements carries 127 bytes, 31 bytes in the lower
+// bytes of each field element plus 3 bytes spread over the 6 usable bits of
+// their high order bytes. The data is prefixed with a version byte and its
+// length as a big-endian uint24, so it may contain any byte sequence.
+//
+// Legacy v1 blobs stored 31 bytes per field element and ended at the first
+// empty field element, they never start with the version byte because every
+// payload starts with blobMsgMagicBytes.
+const (
+	BlobEncodingVersion = 0
+	// MaxBlobDataSize is the largest payload EncodeDataToBlob can fit into a single blob
+	MaxBlobDataSize = roundSize*encodingRounds - blobHeaderSize
+
+	fieldElementSize   = 32
+	fieldElementsCount = 4096
+	roundSize          = 4*31 + 3
+	encodingRounds     = fieldElementsCount / 4
+	blobHeaderSize     = 4
+	blobVersionOffset  = 1
+)
 
-	// Calculate how many field elements we need
-	numFieldElements := (len(data) + UsableBytes - 1) / UsableBytes
-	if numFieldElements > 4096 {
+func EncodeDataToBlob(data []byte) (*kzg4844.Blob, error) {
+	if len(data) > MaxBlobDataSize {
 		return nil, errors.New("data too large for single blob")
 	}
+	var blob kzg4844.Blob
 
-	// Encode data into field elements
-	for i := 0; i < numFieldElements; i++ {
-		start := i * UsableBytes
-		end := start + UsableBytes
-		if end > len(data) {
-			end = len(data)
-		}
-
-		// Copy up to 31 bytes into each field element, leaving the first byte as 0
-		fieldElementStart := i * FieldElementSize
-		blob[fieldElementStart] = 0 // Ensure first byte is 0 for canonical form
-		copy(blob[fieldElementStart+1:fieldElementStart+FieldElementSize], data[start:end])
+	stream := make([]byte, 0, blobHeaderSize+len(data))
+	stream = append(stream, BlobEncodingVersion, byte(len(data)>>16), byte(len(data)>>8), byte(len(data)))
+	stream = append(stream, data...)
+
+	var chunk [roundSize]byte
+	for round := 0; round*roundSize < len(stream); round++ {
+		clear(chunk[:])
+		copy(chunk[:], stream[round*roundSize:])
+		fe := blob[round*4*fieldElementSize:]
+
+		x, y, z := chunk[31], chunk[63], chunk[95]
+		fe[0] = x & 0b0011_1111
+		fe[32] = (y & 0b0000_1111) | ((x & 0b1100_0000) >> 2)
+		fe[64] = z & 0b0011_1111
+		fe[96] = ((z & 0b1100_0000) >> 2) | ((y & 0b1111_0000) >> 4)
+		copy(fe[1:32], chunk[0:31])
+		copy(fe[33:64], chunk[32:63])
+		copy(fe[65:96], chunk[64:95])
+		copy(fe[97:128], chunk[96:127])
 	}
-
 	return &blob, nil
 }
 
+// DecodeBlobToData decodes blobs of both the current and the legacy v1 codec
 func DecodeBlobToData(blob *kzg4844.Blob) ([]byte, error) {
-	const (
-		FieldElementSize = 32
-		UsableBytes      = 31
-	)
+	if blob[blobVersionOffset] != BlobEncodingVersion {
+		return decodeBlobToDataV1(blob), nil
+	}
+	length := int(blob[2])<<16 | int(blob[3])<<8 | int(blob[4])
+	if length > MaxBlobDataSize {
+		return nil, errors.New("invalid blob data length " + strconv.Itoa(length))
+	}
+
+	stream := make([]byte, 0, roundSize*encodingRounds)
+	for round := range encodingRounds {
+		fe := blob[round*4*fieldElementSize:]
+		for i := range 4 {
+			if fe[i*fieldElementSize]&0b1100_0000 != 0 {
+				return nil, errors.New("invalid field element " + strconv.Itoa(round*4+i))
+			}
+		}
+		a, b, c, d := fe[0], fe[32], fe[64], fe[96]
+		x := (a & 0b0011_1111) | ((b & 0b0011_0000) << 2)
+		y := (b & 0b0000_1111) | ((d & 0b0000_1111) << 4)
+		z := (c & 0b0011_1111) | ((d & 0b0011_0000) << 2)
+		stream = append(stream, fe[1:32]...)
+		stream = append(stream, x)
+		stream = append(stream, fe[33:64]...)
+		stream = append(stream, y)
+		stream = append(stream, fe[65:96]...)
+		stream = append(stream, z)
+		stream = append(stream, fe[97:128]...)
+	}
+
+	// everything after the data has to be empty for the encoding to be canonical
+	for i := blobHeaderSize + length; i < len(stream); i++ {
+		if stream[i] != 0 {
+			return nil, errors.New("non-zero byte after the end of the blob data")
+		}
+	}
+	return stream[blobHeaderSize : blobHeaderSize+length], nil
+}
 
+// decodeBlobToDataV1 decodes a legacy v1 blob, the data ends at the first
+// empty field element and trailing zeros are lost
+func decodeBlobToDataV1(blob *kzg4844.Blob) []byte {
 	var data []byte
 
 	// Process each field element
-	for i := 0; i < 4096; i++ {
-		fieldElementStart := i * FieldElementSize
+	for i := 0; i < fieldElementsCount; i++ {
+		fieldElementStart := i * fieldElementSize
 
 		// Extract the data portion (skip first byte which should be 0)
-		fieldData := blob[fieldElementStart+1 : fieldElementStart+FieldElementSize]
+		fieldData := blob[fieldElementStart+1 : fieldElementStart+fieldElementSize]
 
 		// Check if this field element contains any data
 		hasData := false
@@ -76,5 +132,5 @@ func DecodeBlobToData(blob *kzg4844.Blob) ([]byte, error) {
 		data = data[:len(data)-1]
 	}
 
-	return data, nil
+	return data
 }
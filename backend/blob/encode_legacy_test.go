@@ -0,0 +1,58 @@
+package blob
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestDecodeLegacyBlob(t *testing.T) {
+	input := append([]byte{0x2f, 0x39, 0x4d, 0x21}, bytes.Repeat([]byte{0x42}, 1000)...)
+	blobData, err := encodeDataToBlobV1(input)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	decoded, err := DecodeBlobToData(blobData)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+
+	if !bytes.Equal(input, decoded) {
+		t.Errorf("round-trip mismatch\nOriginal: %x\nDecoded:  %x", input, decoded)
+	}
+}
+
+// encodeDataToBlobV1 encodes data with the legacy v1 codec, 31 bytes per field element
+func encodeDataToBlobV1(data []byte) (*kzg4844.Blob, error) {
+	const (
+		// Use 31 bytes per field element to ensure canonical form
+		UsableBytes = 31
+	)
+
+	var blob kzg4844.Blob
+
+	// Calculate how many field elements we need
+	numFieldElements := (len(data) + UsableBytes - 1) / UsableBytes
+	if numFieldElements > fieldElementsCount {
+		return nil, errors.New("data too large for single blob")
+	}
+
+	// Encode data into field elements
+	for i := 0; i < numFieldElements; i++ {
+		start := i * UsableBytes
+		end := start + UsableBytes
+		if end > len(data) {
+			end = len(data)
+		}
+
+		// Copy up to 31 bytes into each field element, leaving the first byte as 0
+		fieldElementStart := i * fieldElementSize
+		blob[fieldElementStart] = 0 // Ensure first byte is 0 for canonical form
+		copy(blob[fieldElementStart+1:fieldElementStart+fieldElementSize], data[start:end])
+	}
+
+	return &blob, nil
+}
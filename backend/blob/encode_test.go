@@ -30,3 +30,36 @@ func TestEncodeDecodeBlob(t *testing.T) {
 		}
 	}
 }
+
+func TestEncodeDecodeBlobZeros(t *testing.T) {
+	tests := [][]byte{
+		{},
+		{0x00},
+		append([]byte("hello"), make([]byte, 64)...),
+		append(append([]byte{0x01}, make([]byte, 31)...), 0x02),
+		bytes.Repeat([]byte{0xff}, blob.MaxBlobDataSize),
+	}
+
+	for i, input := range tests {
+		blobData, err := blob.EncodeDataToBlob(input)
+		if err != nil {
+			t.Fatalf("Test %d: encode error: %v", i, err)
+		}
+
+		decoded, err := blob.DecodeBlobToData(blobData)
+		if err != nil {
+			t.Fatalf("Test %d: decode error: %v", i, err)
+		}
+
+		if !bytes.Equal(input, decoded) {
+			t.Errorf("Test %d: round-trip mismatch\nOriginal: %x\nDecoded:  %x", i, input, decoded)
+		}
+	}
+}
+
+func TestEncodeBlobTooLarge(t *testing.T) {
+	_, err := blob.EncodeDataToBlob(make([]byte, blob.MaxBlobDataSize+1))
+	if err == nil {
+		t.Fatal("expected error for data larger than a blob")
+	}
+}
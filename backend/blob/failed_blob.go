@@ -0,0 +1,129 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+)
+
+// blobs that failed to be downloaded, verified, decoded or stored are
+// retried with an exponential backoff between these delays
+const (
+	failedBlobMinDelay = time.Minute
+	failedBlobMaxDelay = 6 * time.Hour
+)
+
+// failedBlobDelay returns the delay before the next attempt after the given
+// number of failed attempts
+func failedBlobDelay(attempts int32) time.Duration {
+	delay := failedBlobMinDelay
+	for i := int32(1); i < attempts && delay < failedBlobMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, failedBlobMaxDelay)
+}
+
+// addFailedBlob records a blob for the retry worker so its messages are not lost
+func (b *Blob) addFailedBlob(ctx context.Context, blob *SourceBlob, cause error) {
+	log.Warn().Err(cause).
+		Str("versioned_hash", blob.VersionedHash.Hex()).
+		Uint64("block_number", blob.BlockNumber).
+		Msg("failed to ingest blob, scheduling retry")
+	err := b.queries.AddFailedBlob(ctx, dbgen.AddFailedBlobParams{
+		VersionedHash: blob.VersionedHash.Bytes(),
+		BlockNumber:   int64(blob.BlockNumber),
+		BlobIndex:     int32(blob.Index),
+		Error:         cause.Error(),
+		NextAttemptAt: time.Now().Add(failedBlobDelay(1)),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("versioned_hash", blob.VersionedHash.Hex()).Msg("failed to add failed blob")
+	}
+}
+
+// retryFailedBlobsLoop retries failed blobs in the background until ctx is done
+func (b *Blob) retryFailedBlobsLoop(ctx context.Context) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := b.retryFailedBlobs(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to retry failed blobs")
+			}
+		}
+	}
+}
+
+// retryFailedBlobs fetches the failed blobs that are due again and ingests
+// them, blobs that fail again are rescheduled with a longer delay
+func (b *Blob) retryFailedBlobs(ctx context.Context) error {
+	failed, err := b.queries.GetDueFailedBlobs(ctx, time.Now())
+	if err != nil {
+		return errors.New("failed to get due failed blobs: " + err.Error())
+	}
+	for _, f := range failed {
+		versionedHash := common.BytesToHash(f.VersionedHash)
+		err := b.retryFailedBlob(ctx, f)
+		if err != nil {
+			log.Warn().Err(err).
+				Str("versioned_hash", versionedHash.Hex()).
+				Int32("attempts", f.Attempts+1).
+				Msg("failed blob retry failed")
+			err = b.queries.RetryFailedBlob(ctx, dbgen.RetryFailedBlobParams{
+				VersionedHash: f.VersionedHash,
+				Error:         err.Error(),
+				NextAttemptAt: time.Now().Add(failedBlobDelay(f.Attempts + 1)),
+			})
+			if err != nil {
+				return errors.New("failed to reschedule failed blob: " + err.Error())
+			}
+			continue
+		}
+		err = b.queries.RemoveFailedBlob(ctx, f.VersionedHash)
+		if err != nil {
+			return errors.New("failed to remove failed blob: " + err.Error())
+		}
+		log.Info().Str("versioned_hash", versionedHash.Hex()).Msg("ingested failed blob on retry")
+	}
+	return nil
+}
+
+func (b *Blob) retryFailedBlob(ctx context.Context, f dbgen.MessageFailedBlob) error {
+	blob, err := b.source.Blob(ctx, uint64(f.BlockNumber), common.BytesToHash(f.VersionedHash))
+	if err != nil {
+		return errors.New("failed to get blob: " + err.Error())
+	}
+	blobContent, err := checkBlob(blob)
+	if err != nil {
+		return err
+	}
+	if blobContent == nil {
+		return nil
+	}
+	block, err := b.recordBlock(ctx, blob)
+	if errors.Is(err, errNotCanonical) {
+		// the orphaned blob is dropped, its canonical replacement gets
+		// ingested by the indexer
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !b.blockCarriesBlob(blob, block) {
+		return nil
+	}
+	err = b.addBlobToDB(ctx, blob, blobContent, block)
+	if err != nil {
+		return err
+	}
+	b.archiveBlob(ctx, blob)
+	return nil
+}
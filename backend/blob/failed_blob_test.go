@@ -0,0 +1,21 @@
+package blob
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailedBlobDelay(t *testing.T) {
+	for attempts, want := range map[int32]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		5:  16 * time.Minute,
+		9:  4*time.Hour + 16*time.Minute,
+		10: 6 * time.Hour,
+		50: 6 * time.Hour,
+	} {
+		if got := failedBlobDelay(attempts); got != want {
+			t.Errorf("attempt %d: expected %v, got %v", attempts, want, got)
+		}
+	}
+}
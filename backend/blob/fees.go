@@ -0,0 +1,177 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"proto-dankmessaging/backend/dependencies/config"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/rs/zerolog/log"
+)
+
+// number of recent blocks the priority fee is sampled from
+const feeHistoryBlocks = 20
+
+// blobTxGas is the execution gas of a blob transaction without calldata
+const blobTxGas = 21000
+
+// inboxTxGas covers a blob transaction calling the inbox, which emits one
+// event per blob
+const inboxTxGas = 100_000
+
+type txFees struct {
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	BlobFeeCap *big.Int
+}
+
+// maxCost returns the most a transaction with the given gas and blob count
+// can cost under these fee caps
+func (f *txFees) maxCost(gas uint64, blobCount int) *big.Int {
+	cost := new(big.Int).Mul(f.GasFeeCap, new(big.Int).SetUint64(gas))
+	blobGas := new(big.Int).SetUint64(uint64(blobCount) * params.BlobTxBlobGasPerBlob)
+	return cost.Add(cost, blobGas.Mul(blobGas, f.BlobFeeCap))
+}
+
+// storedCaps returns the fee caps as stored in the database, caps beyond
+// int64 are rejected rather than silently truncated
+func (f *txFees) storedCaps() (gasTipCap, gasFeeCap, blobFeeCap int64, err error) {
+	if !f.GasTipCap.IsInt64() || !f.GasFeeCap.IsInt64() || !f.BlobFeeCap.IsInt64() {
+		return 0, 0, 0, errors.New("fee caps exceed the stored range")
+	}
+	return f.GasTipCap.Int64(), f.GasFeeCap.Int64(), f.BlobFeeCap.Int64(), nil
+}
+
+// calcTxFees derives the fee caps from the current base fees and the
+// suggested priority fee using the configured multipliers
+func calcTxFees(c *config.Config, baseFee, blobBaseFee, tip *big.Int) *txFees {
+	gasTipCap := mulFloat(tip, c.TipMultiplier)
+	gasFeeCap := mulFloat(baseFee, c.BaseFeeMultiplier)
+	gasFeeCap.Add(gasFeeCap, gasTipCap)
+	blobFeeCap := mulFloat(blobBaseFee, c.BlobFeeMultiplier)
+	if blobFeeCap.Sign() == 0 {
+		blobFeeCap.SetUint64(params.BlobTxMinBlobGasprice)
+	}
+	return &txFees{
+		GasTipCap:  gasTipCap,
+		GasFeeCap:  gasFeeCap,
+		BlobFeeCap: blobFeeCap,
+	}
+}
+
+func mulFloat(x *big.Int, f float64) *big.Int {
+	res, _ := new(big.Float).Mul(new(big.Float).SetInt(x), big.NewFloat(f)).Int(nil)
+	return res
+}
+
+// marketFees are the current base fees and median priority fee of the chain
+type marketFees struct {
+	BaseFee     *big.Int
+	BlobBaseFee *big.Int
+	Tip         *big.Int
+}
+
+// getMarketFees reads the latest header, eth_blobBaseFee and eth_feeHistory
+func (b *Blob) getMarketFees(ctx context.Context) (*marketFees, error) {
+	head, err := b.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.New("failed to get latest header: " + err.Error())
+	}
+	if head.BaseFee == nil {
+		return nil, errors.New("latest header has no base fee")
+	}
+	blobBaseFee, err := b.blobBaseFee(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := b.suggestTip(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &marketFees{
+		BaseFee:     head.BaseFee,
+		BlobBaseFee: blobBaseFee,
+		Tip:         tip,
+	}, nil
+}
+
+// blobBaseFee returns the blob base fee for the block after head, derived
+// from its excess blob gas if the node does not support eth_blobBaseFee
+func (b *Blob) blobBaseFee(ctx context.Context, head *types.Header) (*big.Int, error) {
+	blobBaseFee, err := b.client.BlobBaseFee(ctx)
+	if err != nil {
+		cfg, ok := chainConfigs[b.dep.Config.ChainId]
+		if !ok || head.ExcessBlobGas == nil {
+			return nil, errors.New("failed to get blob base fee: " + err.Error())
+		}
+		log.Warn().Err(err).Msg("eth_blobBaseFee failed, deriving blob base fee from excess blob gas")
+		blobBaseFee = eip4844.CalcBlobFee(cfg, head)
+	}
+	return blobBaseFee, nil
+}
+
+func (b *Blob) suggestTip(ctx context.Context) (*big.Int, error) {
+	history, err := b.client.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{50})
+	if err != nil {
+		return nil, errors.New("failed to get fee history: " + err.Error())
+	}
+	var rewards []*big.Int
+	for _, reward := range history.Reward {
+		if len(reward) > 0 && reward[0] != nil {
+			rewards = append(rewards, reward[0])
+		}
+	}
+	if len(rewards) == 0 {
+		tip, err := b.client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, errors.New("failed to suggest gas tip cap: " + err.Error())
+		}
+		return tip, nil
+	}
+	slices.SortFunc(rewards, func(a, b *big.Int) int { return a.Cmp(b) })
+	return rewards[len(rewards)/2], nil
+}
+
+// bumpFees returns the suggested fees raised to at least double the fees of
+// the transaction being replaced, the blob pool only accepts a replacement
+// blob transaction if every fee cap is bumped by 100%
+func bumpFees(old, suggested *txFees) *txFees {
+	bump := func(old, suggested *big.Int) *big.Int {
+		return bigMax(new(big.Int).Mul(old, big.NewInt(2)), suggested)
+	}
+	return &txFees{
+		GasTipCap:  bump(old.GasTipCap, suggested.GasTipCap),
+		GasFeeCap:  bump(old.GasFeeCap, suggested.GasFeeCap),
+		BlobFeeCap: bump(old.BlobFeeCap, suggested.BlobFeeCap),
+	}
+}
+
+func bigMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
+
+// checkSpendingCaps refuses a transaction whose worst case cost exceeds the
+// per transaction cap or whose additional cost would push the last 24 hours
+// over the daily cap
+func (b *Blob) checkSpendingCaps(ctx context.Context, cost *big.Int, additional *big.Int) error {
+	if cost.Cmp(new(big.Int).SetUint64(b.dep.Config.MaxTxFee)) > 0 {
+		return errors.New("transaction fee " + cost.String() + " exceeds the per transaction cap")
+	}
+	spent, err := b.queries.GetBlobFeesSince(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return errors.New("failed to get daily blob fees: " + err.Error())
+	}
+	total := new(big.Int).Add(big.NewInt(spent), additional)
+	if total.Cmp(new(big.Int).SetUint64(b.dep.Config.MaxDailyFee)) > 0 {
+		return errors.New("transaction fee " + cost.String() + " exceeds the remaining daily cap")
+	}
+	return nil
+}
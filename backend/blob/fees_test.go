@@ -0,0 +1,75 @@
+package blob
+
+import (
+	"math/big"
+	"testing"
+
+	"proto-dankmessaging/backend/dependencies/config"
+)
+
+func TestCalcTxFees(t *testing.T) {
+	c := &config.Config{
+		BaseFeeMultiplier: 2,
+		BlobFeeMultiplier: 1.5,
+		TipMultiplier:     1,
+	}
+	fees := calcTxFees(c, big.NewInt(10_000_000_000), big.NewInt(1000), big.NewInt(1_000_000_000))
+	if fees.GasTipCap.Cmp(big.NewInt(1_000_000_000)) != 0 {
+		t.Errorf("unexpected tip cap %v", fees.GasTipCap)
+	}
+	if fees.GasFeeCap.Cmp(big.NewInt(21_000_000_000)) != 0 {
+		t.Errorf("unexpected fee cap %v", fees.GasFeeCap)
+	}
+	if fees.BlobFeeCap.Cmp(big.NewInt(1500)) != 0 {
+		t.Errorf("unexpected blob fee cap %v", fees.BlobFeeCap)
+	}
+	// 21000 * 21 gwei + 2 * 131072 * 1500 wei
+	if cost := fees.maxCost(blobTxGas, 2); cost.Cmp(big.NewInt(441_000_393_216_000)) != 0 {
+		t.Errorf("unexpected max cost %v", cost)
+	}
+
+	fees = calcTxFees(c, big.NewInt(1), big.NewInt(0), big.NewInt(0))
+	if fees.BlobFeeCap.Sign() <= 0 {
+		t.Errorf("blob fee cap must be positive, got %v", fees.BlobFeeCap)
+	}
+}
+
+func TestBumpFees(t *testing.T) {
+	old := &txFees{
+		GasTipCap:  big.NewInt(1_000_000_000),
+		GasFeeCap:  big.NewInt(20_000_000_000),
+		BlobFeeCap: big.NewInt(1000),
+	}
+	suggested := &txFees{
+		GasTipCap:  big.NewInt(500_000_000),
+		GasFeeCap:  big.NewInt(50_000_000_000),
+		BlobFeeCap: big.NewInt(1500),
+	}
+	fees := bumpFees(old, suggested)
+	if fees.GasTipCap.Cmp(big.NewInt(2_000_000_000)) != 0 {
+		t.Errorf("unexpected tip cap %v", fees.GasTipCap)
+	}
+	if fees.GasFeeCap.Cmp(big.NewInt(50_000_000_000)) != 0 {
+		t.Errorf("unexpected fee cap %v", fees.GasFeeCap)
+	}
+	if fees.BlobFeeCap.Cmp(big.NewInt(2000)) != 0 {
+		t.Errorf("blob fee cap must be doubled, got %v", fees.BlobFeeCap)
+	}
+}
+
+func TestStoredCaps(t *testing.T) {
+	fees := &txFees{
+		GasTipCap:  big.NewInt(1),
+		GasFeeCap:  big.NewInt(2),
+		BlobFeeCap: big.NewInt(3),
+	}
+	tip, fee, blob, err := fees.storedCaps()
+	if err != nil || tip != 1 || fee != 2 || blob != 3 {
+		t.Errorf("expected caps 1/2/3, got %d/%d/%d (%v)", tip, fee, blob, err)
+	}
+	// repeated doubling must not wrap around
+	fees.BlobFeeCap = new(big.Int).Lsh(big.NewInt(1), 63)
+	if _, _, _, err := fees.storedCaps(); err == nil {
+		t.Error("expected caps beyond int64 to be rejected")
+	}
+}
@@ -0,0 +1,128 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"proto-dankmessaging/backend/dependencies/config"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"time"
+)
+
+// fillPolicy decides when queued messages are flushed, so a few small
+// messages do not pay for a whole blob each
+type fillPolicy struct {
+	target     float64
+	maxLatency time.Duration
+	// blob base fee below which everything queued is flushed, nil if the
+	// fee trigger is disabled
+	cheapFee *big.Int
+
+	// blob base fee of feeBlock, the fee trigger checks it on every tick
+	// but it only changes with a new block
+	feeBlock uint64
+	blobFee  *big.Int
+}
+
+func newFillPolicy(c *config.Config) *fillPolicy {
+	p := &fillPolicy{
+		target:     c.FillTarget,
+		maxLatency: c.MaxLatency,
+	}
+	if c.FlushBlobFee > 0 && c.DABackend != config.DABackendLocal {
+		p.cheapFee = new(big.Int).SetUint64(c.FlushBlobFee)
+	}
+	return p
+}
+
+// flushCount returns how many of the packed blobs go out now and what
+// triggered it, zero if they keep waiting. Only the last blob may be partly
+// filled, the packer closed the others because the next submission did not
+// fit into them.
+func (p *fillPolicy) flushCount(blobs []*packedBlob, now time.Time) (int, dbgen.MessageFlushTrigger) {
+	if len(blobs) == 0 {
+		return 0, ""
+	}
+	for _, blob := range blobs {
+		if now.Sub(blob.queuedAt()) >= p.maxLatency {
+			return len(blobs), dbgen.MessageFlushTriggerDeadline
+		}
+	}
+	if blobs[len(blobs)-1].fillRatio() >= p.target {
+		return len(blobs), dbgen.MessageFlushTriggerFill
+	}
+	return len(blobs) - 1, dbgen.MessageFlushTriggerFill
+}
+
+// flushBlobs returns how many of the packed blobs the next transaction may
+// carry and what triggered the flush, the blob base fee is only checked
+// while the last blob keeps waiting
+func (b *Blob) flushBlobs(ctx context.Context, blobs []*packedBlob, maxBlobs int) (int, dbgen.MessageFlushTrigger, error) {
+	// a blob beyond the transaction limit closes the last one going out
+	count, trigger := b.fill.flushCount(blobs[:min(maxBlobs+1, len(blobs))], time.Now())
+	count = min(count, maxBlobs)
+	if count == min(maxBlobs, len(blobs)) || b.fill.cheapFee == nil {
+		return count, trigger, nil
+	}
+	blobFee, err := b.headBlobFee(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	if blobFee.Cmp(b.fill.cheapFee) < 0 {
+		return min(maxBlobs, len(blobs)), dbgen.MessageFlushTriggerFee, nil
+	}
+	return count, trigger, nil
+}
+
+// headBlobFee returns the blob base fee at the latest block, it is only
+// fetched again once a new block arrived
+func (b *Blob) headBlobFee(ctx context.Context) (*big.Int, error) {
+	head, err := b.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.New("failed to get latest header: " + err.Error())
+	}
+	if b.fill.blobFee != nil && b.fill.feeBlock == head.Number.Uint64() {
+		return b.fill.blobFee, nil
+	}
+	blobFee, err := b.blobBaseFee(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	b.fill.feeBlock = head.Number.Uint64()
+	b.fill.blobFee = blobFee
+	return blobFee, nil
+}
+
+// fillRatio returns how much of a blob the compressed payload takes up, the
+// packer fills blobs by their compressed size as well
+func (blob *packedBlob) fillRatio() float64 {
+	return float64(len(blob.payload)) / MaxBlobDataSize
+}
+
+// queuedAt returns when the oldest submission of the blob was queued
+func (blob *packedBlob) queuedAt() time.Time {
+	oldest := blob.submissions[0].QueuedAt
+	for _, msg := range blob.submissions[1:] {
+		if msg.QueuedAt.Before(oldest) {
+			oldest = msg.QueuedAt
+		}
+	}
+	return oldest
+}
+
+// recordBatch stores the fill ratio and the wait time of a flushed blob,
+// nonce is nil for backends without transactions
+func recordBatch(ctx context.Context, qtx *dbgen.Queries, blob *packedBlob, versionedHash []byte, nonce *int64, trigger dbgen.MessageFlushTrigger) error {
+	err := qtx.AddBlobBatch(ctx, dbgen.AddBlobBatchParams{
+		VersionedHash: versionedHash,
+		Nonce:         nonce,
+		MessageCount:  int32(len(blob.submissions)),
+		FillRatio:     blob.fillRatio(),
+		WaitMs:        time.Since(blob.queuedAt()).Milliseconds(),
+		Trigger:       trigger,
+	})
+	if err != nil {
+		return errors.New("failed to add blob batch: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package blob
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+)
+
+func TestFlushCount(t *testing.T) {
+	now := time.Now()
+	policy := &fillPolicy{target: 0.5, maxLatency: time.Minute}
+	blob := func(size int, age time.Duration) *packedBlob {
+		return &packedBlob{
+			submissions: []dbgen.MessageBlobSubmission{{QueuedAt: now.Add(-age)}},
+			payload:     make([]byte, size),
+		}
+	}
+	full := MaxBlobDataSize
+	tests := []struct {
+		name    string
+		blobs   []*packedBlob
+		count   int
+		trigger dbgen.MessageFlushTrigger
+	}{
+		{"empty", nil, 0, ""},
+		{"small and fresh", []*packedBlob{blob(200, time.Second)}, 0, dbgen.MessageFlushTriggerFill},
+		{"filled", []*packedBlob{blob(full*3/4, time.Second)}, 1, dbgen.MessageFlushTriggerFill},
+		{"small and old", []*packedBlob{blob(200, 2*time.Minute)}, 1, dbgen.MessageFlushTriggerDeadline},
+		{"closed blobs go out", []*packedBlob{blob(full, time.Second), blob(200, time.Second)}, 1, dbgen.MessageFlushTriggerFill},
+		{"old closed blob takes the rest along", []*packedBlob{blob(full, 2*time.Minute), blob(200, time.Second)}, 2, dbgen.MessageFlushTriggerDeadline},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, trigger := policy.flushCount(tt.blobs, now)
+			if count != tt.count || (count > 0 && trigger != tt.trigger) {
+				t.Errorf("expected %d blobs flushed by %q, got %d by %q", tt.count, tt.trigger, count, trigger)
+			}
+		})
+	}
+}
+
+func TestFillRatioCompressed(t *testing.T) {
+	now := time.Now()
+	var msgs []dbgen.MessageBlobSubmission
+	for i := range 40 {
+		msgs = append(msgs, dbgen.MessageBlobSubmission{
+			Index:    bytes.Repeat([]byte{byte(i)}, 32),
+			Message:  bytes.Repeat([]byte{0x42}, 4000),
+			QueuedAt: now,
+		})
+	}
+	blobs, _, err := packSubmissions(msgs)
+	if err != nil || len(blobs) != 1 {
+		t.Fatalf("expected a single blob, got %d (%v)", len(blobs), err)
+	}
+
+	// raw messages filling more than the target do not count, the blob
+	// fills up by its compressed size as the packer sees it
+	policy := &fillPolicy{target: 0.5, maxLatency: time.Minute}
+	if ratio := blobs[0].fillRatio(); ratio >= policy.target {
+		t.Fatalf("expected the compressed blob below the target, got %f", ratio)
+	}
+	if count, _ := policy.flushCount(blobs, now); count != 0 {
+		t.Errorf("expected the blob to keep waiting, got %d flushed", count)
+	}
+}
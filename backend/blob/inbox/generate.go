@@ -0,0 +1,5 @@
+// Package inbox contains the bindings of the BlobInbox contract in contracts/src,
+// BlobInbox.abi is the output of `forge inspect BlobInbox abi`
+package inbox
+
+//go:generate go tool abigen --abi BlobInbox.abi --pkg inbox --type BlobInbox --out inbox.go
@@ -0,0 +1,356 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package inbox
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// BlobInboxMetaData contains all meta data concerning the BlobInbox contract.
+var BlobInboxMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"announce\",\"inputs\":[{\"name\":\"version\",\"type\":\"uint8\",\"internalType\":\"uint8\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"event\",\"name\":\"BlobAnnounced\",\"inputs\":[{\"name\":\"sender\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"versionedHash\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"version\",\"type\":\"uint8\",\"indexed\":false,\"internalType\":\"uint8\"}],\"anonymous\":false},{\"type\":\"error\",\"name\":\"NoBlobs\",\"inputs\":[]}]",
+}
+
+// BlobInboxABI is the input ABI used to generate the binding from.
+// Deprecated: Use BlobInboxMetaData.ABI instead.
+var BlobInboxABI = BlobInboxMetaData.ABI
+
+// BlobInbox is an auto generated Go binding around an Ethereum contract.
+type BlobInbox struct {
+	BlobInboxCaller     // Read-only binding to the contract
+	BlobInboxTransactor // Write-only binding to the contract
+	BlobInboxFilterer   // Log filterer for contract events
+}
+
+// BlobInboxCaller is an auto generated read-only Go binding around an Ethereum contract.
+type BlobInboxCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// BlobInboxTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type BlobInboxTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// BlobInboxFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type BlobInboxFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// BlobInboxSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type BlobInboxSession struct {
+	Contract     *BlobInbox        // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// BlobInboxCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type BlobInboxCallerSession struct {
+	Contract *BlobInboxCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts    // Call options to use throughout this session
+}
+
+// BlobInboxTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type BlobInboxTransactorSession struct {
+	Contract     *BlobInboxTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts    // Transaction auth options to use throughout this session
+}
+
+// BlobInboxRaw is an auto generated low-level Go binding around an Ethereum contract.
+type BlobInboxRaw struct {
+	Contract *BlobInbox // Generic contract binding to access the raw methods on
+}
+
+// BlobInboxCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type BlobInboxCallerRaw struct {
+	Contract *BlobInboxCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// BlobInboxTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type BlobInboxTransactorRaw struct {
+	Contract *BlobInboxTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewBlobInbox creates a new instance of BlobInbox, bound to a specific deployed contract.
+func NewBlobInbox(address common.Address, backend bind.ContractBackend) (*BlobInbox, error) {
+	contract, err := bindBlobInbox(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobInbox{BlobInboxCaller: BlobInboxCaller{contract: contract}, BlobInboxTransactor: BlobInboxTransactor{contract: contract}, BlobInboxFilterer: BlobInboxFilterer{contract: contract}}, nil
+}
+
+// NewBlobInboxCaller creates a new read-only instance of BlobInbox, bound to a specific deployed contract.
+func NewBlobInboxCaller(address common.Address, caller bind.ContractCaller) (*BlobInboxCaller, error) {
+	contract, err := bindBlobInbox(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobInboxCaller{contract: contract}, nil
+}
+
+// NewBlobInboxTransactor creates a new write-only instance of BlobInbox, bound to a specific deployed contract.
+func NewBlobInboxTransactor(address common.Address, transactor bind.ContractTransactor) (*BlobInboxTransactor, error) {
+	contract, err := bindBlobInbox(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobInboxTransactor{contract: contract}, nil
+}
+
+// NewBlobInboxFilterer creates a new log filterer instance of BlobInbox, bound to a specific deployed contract.
+func NewBlobInboxFilterer(address common.Address, filterer bind.ContractFilterer) (*BlobInboxFilterer, error) {
+	contract, err := bindBlobInbox(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobInboxFilterer{contract: contract}, nil
+}
+
+// bindBlobInbox binds a generic wrapper to an already deployed contract.
+func bindBlobInbox(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := BlobInboxMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_BlobInbox *BlobInboxRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _BlobInbox.Contract.BlobInboxCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_BlobInbox *BlobInboxRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _BlobInbox.Contract.BlobInboxTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_BlobInbox *BlobInboxRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _BlobInbox.Contract.BlobInboxTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_BlobInbox *BlobInboxCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _BlobInbox.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_BlobInbox *BlobInboxTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _BlobInbox.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_BlobInbox *BlobInboxTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _BlobInbox.Contract.contract.Transact(opts, method, params...)
+}
+
+// Announce is a paid mutator transaction binding the contract method 0x8895bd4f.
+//
+// Solidity: function announce(uint8 version) returns()
+func (_BlobInbox *BlobInboxTransactor) Announce(opts *bind.TransactOpts, version uint8) (*types.Transaction, error) {
+	return _BlobInbox.contract.Transact(opts, "announce", version)
+}
+
+// Announce is a paid mutator transaction binding the contract method 0x8895bd4f.
+//
+// Solidity: function announce(uint8 version) returns()
+func (_BlobInbox *BlobInboxSession) Announce(version uint8) (*types.Transaction, error) {
+	return _BlobInbox.Contract.Announce(&_BlobInbox.TransactOpts, version)
+}
+
+// Announce is a paid mutator transaction binding the contract method 0x8895bd4f.
+//
+// Solidity: function announce(uint8 version) returns()
+func (_BlobInbox *BlobInboxTransactorSession) Announce(version uint8) (*types.Transaction, error) {
+	return _BlobInbox.Contract.Announce(&_BlobInbox.TransactOpts, version)
+}
+
+// BlobInboxBlobAnnouncedIterator is returned from FilterBlobAnnounced and is used to iterate over the raw logs and unpacked data for BlobAnnounced events raised by the BlobInbox contract.
+type BlobInboxBlobAnnouncedIterator struct {
+	Event *BlobInboxBlobAnnounced // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *BlobInboxBlobAnnouncedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(BlobInboxBlobAnnounced)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(BlobInboxBlobAnnounced)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *BlobInboxBlobAnnouncedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *BlobInboxBlobAnnouncedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// BlobInboxBlobAnnounced represents a BlobAnnounced event raised by the BlobInbox contract.
+type BlobInboxBlobAnnounced struct {
+	Sender        common.Address
+	VersionedHash [32]byte
+	Version       uint8
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterBlobAnnounced is a free log retrieval operation binding the contract event 0xcf020a92e1a7fe24e7b0a07785cdc19df6fed06585ff5dd2868bbfc8a4add8b0.
+//
+// Solidity: event BlobAnnounced(address indexed sender, bytes32 indexed versionedHash, uint8 version)
+func (_BlobInbox *BlobInboxFilterer) FilterBlobAnnounced(opts *bind.FilterOpts, sender []common.Address, versionedHash [][32]byte) (*BlobInboxBlobAnnouncedIterator, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+	var versionedHashRule []interface{}
+	for _, versionedHashItem := range versionedHash {
+		versionedHashRule = append(versionedHashRule, versionedHashItem)
+	}
+
+	logs, sub, err := _BlobInbox.contract.FilterLogs(opts, "BlobAnnounced", senderRule, versionedHashRule)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobInboxBlobAnnouncedIterator{contract: _BlobInbox.contract, event: "BlobAnnounced", logs: logs, sub: sub}, nil
+}
+
+// WatchBlobAnnounced is a free log subscription operation binding the contract event 0xcf020a92e1a7fe24e7b0a07785cdc19df6fed06585ff5dd2868bbfc8a4add8b0.
+//
+// Solidity: event BlobAnnounced(address indexed sender, bytes32 indexed versionedHash, uint8 version)
+func (_BlobInbox *BlobInboxFilterer) WatchBlobAnnounced(opts *bind.WatchOpts, sink chan<- *BlobInboxBlobAnnounced, sender []common.Address, versionedHash [][32]byte) (event.Subscription, error) {
+
+	var senderRule []interface{}
+	for _, senderItem := range sender {
+		senderRule = append(senderRule, senderItem)
+	}
+	var versionedHashRule []interface{}
+	for _, versionedHashItem := range versionedHash {
+		versionedHashRule = append(versionedHashRule, versionedHashItem)
+	}
+
+	logs, sub, err := _BlobInbox.contract.WatchLogs(opts, "BlobAnnounced", senderRule, versionedHashRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(BlobInboxBlobAnnounced)
+				if err := _BlobInbox.contract.UnpackLog(event, "BlobAnnounced", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseBlobAnnounced is a log parse operation binding the contract event 0xcf020a92e1a7fe24e7b0a07785cdc19df6fed06585ff5dd2868bbfc8a4add8b0.
+//
+// Solidity: event BlobAnnounced(address indexed sender, bytes32 indexed versionedHash, uint8 version)
+func (_BlobInbox *BlobInboxFilterer) ParseBlobAnnounced(log types.Log) (*BlobInboxBlobAnnounced, error) {
+	event := new(BlobInboxBlobAnnounced)
+	if err := _BlobInbox.contract.UnpackLog(event, "BlobAnnounced", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
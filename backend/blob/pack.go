@@ -0,0 +1,159 @@
+package blob
+
+import (
+	"errors"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/params"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxBlobsPerTx caps the blobs of a single transaction below the fork limit,
+// geth's blob pool rejects transactions carrying more than 7 blobs and
+// PeerDAS limits them to 6
+const maxBlobsPerTx = 6
+
+var chainConfigs = map[uint64]*params.ChainConfig{
+	params.MainnetChainConfig.ChainID.Uint64(): params.MainnetChainConfig,
+	params.SepoliaChainConfig.ChainID.Uint64(): params.SepoliaChainConfig,
+	params.HoleskyChainConfig.ChainID.Uint64(): params.HoleskyChainConfig,
+	params.HoodiChainConfig.ChainID.Uint64():   params.HoodiChainConfig,
+}
+
+// packedBlob holds the queued submissions that go into a single blob
+type packedBlob struct {
+	submissions []dbgen.MessageBlobSubmission
+	payload     []byte
+}
+
+// emptyPayloadSize is the size of a payload without messages
+var emptyPayloadSize = len(blobMsgMagicBytes) + payloadHeaderSize
+
+// compressionSlack covers the framing zstd may add on top of the bytes a
+// message adds to the compressed payload
+const compressionSlack = 64
+
+// packSubmissions greedily splits the queued submissions into as many blob
+// payloads as needed, submissions too large to ever fit into a blob on their
+// own are returned separately. Blobs are filled by their compressed size,
+// the uncompressed size of the messages added since the last compression
+// bounds it from above and the payload is only compressed again once that
+// bound no longer fits.
+func packSubmissions(msgs []dbgen.MessageBlobSubmission) ([]*packedBlob, []dbgen.MessageBlobSubmission, error) {
+	var (
+		blobs     []*packedBlob
+		oversized []dbgen.MessageBlobSubmission
+		current   []dbgen.MessageBlobSubmission
+		// payload of current if it is up to date, size bounds its length
+		payload []byte
+		size    = emptyPayloadSize
+	)
+	flush := func() error {
+		if len(current) == 0 {
+			return nil
+		}
+		if payload == nil {
+			var err error
+			payload, err = marshalBlobContent(current)
+			if err != nil {
+				return err
+			}
+		}
+		if len(payload) > MaxBlobDataSize {
+			return errors.New("packed payload exceeds the blob capacity")
+		}
+		blobs = append(blobs, &packedBlob{submissions: current, payload: payload})
+		current = nil
+		payload = nil
+		size = emptyPayloadSize
+		return nil
+	}
+	for _, msg := range msgs {
+		if !FitsBlob(msg) {
+			oversized = append(oversized, msg)
+			continue
+		}
+		msgSize := messageFieldSize(msg) + compressionSlack
+		if size+msgSize > MaxBlobDataSize && len(current) > 0 {
+			next := append(slices.Clip(current), msg)
+			nextPayload, err := marshalBlobContent(next)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(nextPayload) <= MaxBlobDataSize {
+				current = next
+				payload = nextPayload
+				size = len(nextPayload)
+				continue
+			}
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+		}
+		current = append(current, msg)
+		payload = nil
+		size += msgSize
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+	return blobs, oversized, nil
+}
+
+// packCache keeps the blobs packed from the last claimed submissions, they
+// mostly stay the same while a blob fills up and packing compresses all of
+// them again
+type packCache struct {
+	msgs      []dbgen.MessageBlobSubmission
+	blobs     []*packedBlob
+	oversized []dbgen.MessageBlobSubmission
+}
+
+// pack returns the packed blobs of the cache if the submissions did not
+// change since they were packed
+func (c *packCache) pack(msgs []dbgen.MessageBlobSubmission) ([]*packedBlob, []dbgen.MessageBlobSubmission, error) {
+	unchanged := slices.EqualFunc(msgs, c.msgs, func(a, b dbgen.MessageBlobSubmission) bool {
+		return a.ID == b.ID && a.UpdatedAt.Equal(b.UpdatedAt)
+	})
+	if unchanged && c.msgs != nil {
+		return c.blobs, c.oversized, nil
+	}
+	blobs, oversized, err := packSubmissions(msgs)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.msgs, c.blobs, c.oversized = msgs, blobs, oversized
+	return blobs, oversized, nil
+}
+
+// FitsBlob reports whether the submission fits into a blob on its own
+func FitsBlob(msg dbgen.MessageBlobSubmission) bool {
+	return emptyPayloadSize+messageFieldSize(msg) <= MaxBlobDataSize
+}
+
+// messageFieldSize returns the number of bytes a submission adds to the
+// marshalled BlobContent
+func messageFieldSize(msg dbgen.MessageBlobSubmission) int {
+	size := proto.Size(submissionToMessage(msg))
+	return protowire.SizeTag(1) + protowire.SizeBytes(size)
+}
+
+func submissionToMessage(msg dbgen.MessageBlobSubmission) *Message {
+	return &Message{
+		EphemeralPubkey: msg.Pubkey,
+		SearchIndex:     msg.Index,
+		Message:         msg.Message,
+	}
+}
+
+func marshalBlobContent(msgs []dbgen.MessageBlobSubmission) ([]byte, error) {
+	blob := &BlobContent{
+		Messages: make([]*Message, 0, len(msgs)),
+	}
+	for _, msg := range msgs {
+		blob.Messages = append(blob.Messages, submissionToMessage(msg))
+	}
+	return encodePayload(blob)
+}
@@ -0,0 +1,126 @@
+package blob
+
+import (
+	"bytes"
+	"crypto/rand"
+	"slices"
+	"testing"
+
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+)
+
+func TestPackSubmissions(t *testing.T) {
+	var msgs []dbgen.MessageBlobSubmission
+	for i := range 100 {
+		// encrypted messages do not compress
+		message := make([]byte, 4000)
+		rand.Read(message)
+		msgs = append(msgs, dbgen.MessageBlobSubmission{
+			ID:      int32(i),
+			Index:   bytes.Repeat([]byte{byte(i)}, 32),
+			Message: message,
+			Pubkey:  bytes.Repeat([]byte{0x02}, 33),
+		})
+	}
+	msgs = append(msgs, dbgen.MessageBlobSubmission{
+		ID:      100,
+		Message: make([]byte, MaxBlobDataSize),
+	})
+
+	blobs, oversized, err := packSubmissions(msgs)
+	if err != nil {
+		t.Fatalf("pack error: %v", err)
+	}
+	if len(oversized) != 1 || oversized[0].ID != 100 {
+		t.Fatalf("expected submission 100 to be oversized, got %v", oversized)
+	}
+	if len(blobs) != 4 {
+		t.Fatalf("expected 4 blobs, got %d", len(blobs))
+	}
+
+	packed := 0
+	for i, blob := range blobs {
+		if len(blob.payload) > MaxBlobDataSize {
+			t.Errorf("Blob %d: payload of %d bytes exceeds blob capacity", i, len(blob.payload))
+		}
+		content, err := decodePayload(blob.payload)
+		if err != nil {
+			t.Fatalf("Blob %d: decode error: %v", i, err)
+		}
+		if len(content.Messages) != len(blob.submissions) {
+			t.Errorf("Blob %d: %d messages for %d submissions", i, len(content.Messages), len(blob.submissions))
+		}
+		packed += len(blob.submissions)
+	}
+	if packed != 100 {
+		t.Errorf("expected 100 packed submissions, got %d", packed)
+	}
+}
+
+func TestPackCompressedSubmissions(t *testing.T) {
+	var msgs []dbgen.MessageBlobSubmission
+	raw := 0
+	for i := range 100 {
+		msg := dbgen.MessageBlobSubmission{
+			ID:      int32(i),
+			Index:   bytes.Repeat([]byte{byte(i)}, 32),
+			Message: bytes.Repeat([]byte{0x42}, 4000),
+			Pubkey:  bytes.Repeat([]byte{0x02}, 33),
+		}
+		raw += messageFieldSize(msg)
+		msgs = append(msgs, msg)
+	}
+	if raw <= MaxBlobDataSize {
+		t.Fatalf("expected more than a blob of raw messages, got %d bytes", raw)
+	}
+
+	// compression lets all of them share a single blob
+	blobs, oversized, err := packSubmissions(msgs)
+	if err != nil {
+		t.Fatalf("pack error: %v", err)
+	}
+	if len(blobs) != 1 || len(oversized) != 0 {
+		t.Fatalf("expected a single blob, got %d and %d oversized", len(blobs), len(oversized))
+	}
+	if len(blobs[0].payload) > MaxBlobDataSize {
+		t.Errorf("payload of %d bytes exceeds blob capacity", len(blobs[0].payload))
+	}
+	content, err := decodePayload(blobs[0].payload)
+	if err != nil || len(content.Messages) != 100 {
+		t.Fatalf("expected 100 messages, got %v", err)
+	}
+}
+
+func TestFitsBlob(t *testing.T) {
+	if !FitsBlob(dbgen.MessageBlobSubmission{Message: make([]byte, MaxBlobDataSize-100)}) {
+		t.Error("expected a message just below the blob capacity to fit")
+	}
+	if FitsBlob(dbgen.MessageBlobSubmission{Message: make([]byte, MaxBlobDataSize)}) {
+		t.Error("expected a message of the blob capacity not to fit")
+	}
+}
+
+func TestPackCache(t *testing.T) {
+	msgs := []dbgen.MessageBlobSubmission{
+		{ID: 1, Index: []byte{0x01}, Message: []byte("a")},
+		{ID: 2, Index: []byte{0x02}, Message: []byte("b")},
+	}
+	cache := &packCache{}
+	first, _, err := cache.pack(msgs)
+	if err != nil {
+		t.Fatalf("pack error: %v", err)
+	}
+
+	// the same claimed submissions are not packed again
+	again, _, err := cache.pack(slices.Clone(msgs))
+	if err != nil || len(again) != 1 || again[0] != first[0] {
+		t.Fatalf("expected the cached blob, got %v (%v)", again, err)
+	}
+
+	// a new submission repacks the blob
+	msgs = append(msgs, dbgen.MessageBlobSubmission{ID: 3, Index: []byte{0x03}, Message: []byte("c")})
+	repacked, _, err := cache.pack(msgs)
+	if err != nil || len(repacked) != 1 || repacked[0] == first[0] || len(repacked[0].submissions) != 3 {
+		t.Fatalf("expected a repacked blob of 3 submissions, got %v (%v)", repacked, err)
+	}
+}
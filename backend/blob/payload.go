@@ -0,0 +1,87 @@
+package blob
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/proto"
+)
+
+// Payloads start with blobMsgMagicBytes followed by a header of the payload
+// version and the compression of the BlobContent that follows. Legacy
+// payloads carry the BlobContent right after the magic bytes, they start
+// with the tag of its first field and never with a payload version.
+const (
+	payloadVersion    = 0x01
+	payloadHeaderSize = 2
+
+	compressionNone = 0x00
+	compressionZstd = 0x01
+
+	// upper bound for decompressed BlobContent, guards against
+	// decompression bombs in foreign blobs
+	maxDecompressedSize = 4 << 20
+)
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+)
+
+// encodePayload marshals the BlobContent and compresses it if that makes the
+// payload smaller
+func encodePayload(content *BlobContent) ([]byte, error) {
+	raw, err := proto.Marshal(content)
+	if err != nil {
+		return nil, errors.New("failed to marshal blob content: " + err.Error())
+	}
+	compression := byte(compressionNone)
+	body := raw
+	if compressed := zstdEncoder.EncodeAll(raw, nil); len(compressed) < len(raw) {
+		compression = compressionZstd
+		body = compressed
+	}
+	payload := make([]byte, 0, len(blobMsgMagicBytes)+payloadHeaderSize+len(body))
+	payload = append(payload, blobMsgMagicBytes...)
+	payload = append(payload, payloadVersion, compression)
+	return append(payload, body...), nil
+}
+
+// isPayload reports whether the decoded blob data is an OnlyDanks payload
+func isPayload(data []byte) bool {
+	return bytes.HasPrefix(data, blobMsgMagicBytes)
+}
+
+// decodePayload parses the BlobContent of both current and legacy payloads
+func decodePayload(data []byte) (*BlobContent, error) {
+	if !isPayload(data) {
+		return nil, errors.New("missing magic bytes")
+	}
+	body := data[len(blobMsgMagicBytes):]
+	if len(body) > 0 && body[0] == payloadVersion {
+		if len(body) < payloadHeaderSize {
+			return nil, errors.New("truncated payload header")
+		}
+		compression := body[1]
+		body = body[payloadHeaderSize:]
+		switch compression {
+		case compressionNone:
+		case compressionZstd:
+			decompressed, err := zstdDecoder.DecodeAll(body, nil)
+			if err != nil {
+				return nil, errors.New("failed to decompress blob content: " + err.Error())
+			}
+			body = decompressed
+		default:
+			return nil, errors.New("unknown compression " + strconv.Itoa(int(compression)))
+		}
+	}
+	var content BlobContent
+	err := proto.Unmarshal(body, &content)
+	if err != nil {
+		return nil, errors.New("failed to unmarshal blob content: " + err.Error())
+	}
+	return &content, nil
+}
@@ -0,0 +1,57 @@
+package blob
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPayloadRoundTrip(t *testing.T) {
+	tests := []*BlobContent{
+		{Messages: []*Message{{EphemeralPubkey: []byte{0x02}, SearchIndex: []byte{0x01}, Message: []byte("hi")}}},
+		{Messages: []*Message{{EphemeralPubkey: bytes.Repeat([]byte{0x02}, 33), Message: bytes.Repeat([]byte("hello"), 1000)}}},
+	}
+
+	for i, content := range tests {
+		payload, err := encodePayload(content)
+		if err != nil {
+			t.Fatalf("Test %d: encode error: %v", i, err)
+		}
+		decoded, err := decodePayload(payload)
+		if err != nil {
+			t.Fatalf("Test %d: decode error: %v", i, err)
+		}
+		if !proto.Equal(content, decoded) {
+			t.Errorf("Test %d: round-trip mismatch", i)
+		}
+	}
+
+	payload, _ := encodePayload(tests[1])
+	if payload[len(blobMsgMagicBytes)+1] != compressionZstd {
+		t.Errorf("expected compressible payload to be compressed")
+	}
+}
+
+func TestDecodeLegacyPayload(t *testing.T) {
+	content := &BlobContent{Messages: []*Message{{SearchIndex: []byte{0x01}, Message: []byte("legacy")}}}
+	raw, err := proto.Marshal(content)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	decoded, err := decodePayload(append(append([]byte{}, blobMsgMagicBytes...), raw...))
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if !proto.Equal(content, decoded) {
+		t.Errorf("round-trip mismatch")
+	}
+}
+
+func TestDecodePayloadBomb(t *testing.T) {
+	bomb := zstdEncoder.EncodeAll(make([]byte, maxDecompressedSize+1), nil)
+	payload := append(append([]byte{}, blobMsgMagicBytes...), payloadVersion, compressionZstd)
+	if _, err := decodePayload(append(payload, bomb...)); err == nil {
+		t.Fatal("expected oversized payload to be rejected")
+	}
+}
@@ -0,0 +1,215 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"proto-dankmessaging/backend/dependencies/config"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog/log"
+)
+
+// number of recorded blocks compared against the canonical chain, blocks
+// below the finalized block are pruned so this only has to cover the
+// unfinalized part of the chain
+const reorgSearchDepth = 128
+
+// updateFinalized stores the finalized block so the API can hide messages
+// from blocks that may still be reorged and prunes the recorded blocks below it
+func (b *Blob) updateFinalized(ctx context.Context) error {
+	finalized, err := b.client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil {
+		return errors.New("failed to get finalized header: " + err.Error())
+	}
+	return b.inTx(ctx, func(qtx *dbgen.Queries) error {
+		err := qtx.SetFinalizedBlock(ctx, finalized.Number.Int64())
+		if err != nil {
+			return errors.New("failed to set finalized block: " + err.Error())
+		}
+		blockNumber := finalized.Number.Int64()
+		err = qtx.FinalizeMessages(ctx, &blockNumber)
+		if err != nil {
+			return errors.New("failed to finalize messages: " + err.Error())
+		}
+		err = qtx.RemoveChainBlocksBefore(ctx, finalized.Number.Int64())
+		if err != nil {
+			return errors.New("failed to prune chain blocks: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// handleReorg compares the recorded blocks with the canonical chain by
+// following the parent hashes of the canonical headers, messages from
+// orphaned blocks are removed and the update cursor is rewound to the first
+// block that may have been orphaned so the canonical blobs get ingested again
+func (b *Blob) handleReorg(ctx context.Context) error {
+	blocks, err := b.queries.GetRecentChainBlocks(ctx, reorgSearchDepth)
+	if err != nil {
+		return errors.New("failed to get recent chain blocks: " + err.Error())
+	}
+	forkPoint, err := findForkPoint(ctx, b.client, blocks)
+	if err != nil {
+		return err
+	}
+	if forkPoint < 0 {
+		return nil
+	}
+	log.Warn().Int64("fork_block", forkPoint).Msg("chain reorg detected, rolling back orphaned messages")
+	err = b.inTx(ctx, func(qtx *dbgen.Queries) error {
+		// our own messages stay known to the relay until their blob is
+		// included again
+		err := qtx.UnconfirmMessagesFromBlock(ctx, &forkPoint)
+		if err != nil {
+			return errors.New("failed to unconfirm orphaned messages: " + err.Error())
+		}
+		err = qtx.RemoveMessagesFromBlock(ctx, &forkPoint)
+		if err != nil {
+			return errors.New("failed to remove orphaned messages: " + err.Error())
+		}
+		err = qtx.RemoveIngestedBlobsFromBlock(ctx, forkPoint)
+		if err != nil {
+			return errors.New("failed to remove orphaned blobs: " + err.Error())
+		}
+		err = qtx.RemoveFailedBlobsFromBlock(ctx, forkPoint)
+		if err != nil {
+			return errors.New("failed to remove orphaned failed blobs: " + err.Error())
+		}
+		err = qtx.RemoveChainBlocksFrom(ctx, forkPoint)
+		if err != nil {
+			return errors.New("failed to remove orphaned blocks: " + err.Error())
+		}
+		err = qtx.RewindBlobUpdate(ctx, forkPoint)
+		if err != nil {
+			return errors.New("failed to rewind blob update: " + err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// the messages are already rolled back, the archive is cleaned up on
+	// the next reorg otherwise
+	err = b.pruneArchive(ctx, forkPoint)
+	if err != nil {
+		log.Error().Err(err).Int64("fork_block", forkPoint).Msg("failed to prune archive")
+	}
+	return nil
+}
+
+// headerReader is the part of the client the fork search needs
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// findForkPoint walks the canonical chain down from the highest of the
+// recorded blocks, which are ordered by descending block number, and returns
+// the block right above the highest one that is still canonical, or -1 if
+// they all are. Blocks between recorded ones are unknown, so everything
+// above the last canonical block counts as possibly orphaned.
+func findForkPoint(ctx context.Context, chain headerReader, blocks []dbgen.MessageChainBlock) (int64, error) {
+	if len(blocks) == 0 {
+		return -1, nil
+	}
+	header, err := chain.HeaderByNumber(ctx, big.NewInt(blocks[0].BlockNumber))
+	if err != nil {
+		return -1, errors.New("failed to get header: " + err.Error())
+	}
+	forkPoint := int64(-1)
+	for _, block := range blocks {
+		for header.Number.Int64() > block.BlockNumber {
+			header, err = chain.HeaderByHash(ctx, header.ParentHash)
+			if err != nil {
+				return -1, errors.New("failed to get parent header: " + err.Error())
+			}
+		}
+		if header.Hash() == common.BytesToHash(block.BlockHash) {
+			if forkPoint < 0 {
+				return -1, nil
+			}
+			return block.BlockNumber + 1, nil
+		}
+		forkPoint = block.BlockNumber
+	}
+	// none of the recorded blocks is canonical anymore
+	return forkPoint, nil
+}
+
+// recordCursorBlock records the canonical block right below the cursor, so
+// reorgs are noticed even across blocks without any of our blobs
+func (b *Blob) recordCursorBlock(ctx context.Context, cursor BlobCursor) error {
+	if cursor.BlockNumber == 0 {
+		return nil
+	}
+	header, err := b.client.HeaderByNumber(ctx, new(big.Int).SetUint64(cursor.BlockNumber-1))
+	if err != nil {
+		return errors.New("failed to get header: " + err.Error())
+	}
+	err = b.queries.AddChainBlock(ctx, dbgen.AddChainBlockParams{
+		BlockNumber: header.Number.Int64(),
+		BlockHash:   header.Hash().Bytes(),
+		ParentHash:  header.ParentHash.Bytes(),
+	})
+	if err != nil {
+		return errors.New("failed to add chain block: " + err.Error())
+	}
+	return nil
+}
+
+// errNotCanonical is returned by recordBlock for blobs of orphaned blocks
+var errNotCanonical = errors.New("blob block is not canonical")
+
+// recordBlock checks that a blob of the source is part of the canonical
+// chain and records its block for later reorg detection
+func (b *Blob) recordBlock(ctx context.Context, blob *SourceBlob) (*types.Block, error) {
+	// the local backend numbers its blocks without a chain, there is nothing
+	// to check them against
+	if b.dep.Config.DABackend == config.DABackendLocal {
+		return types.NewBlockWithHeader(&types.Header{
+			Number: new(big.Int).SetUint64(blob.BlockNumber),
+			Time:   uint64(blob.BlockTimestamp.Unix()),
+		}), nil
+	}
+	block, err := b.client.BlockByNumber(ctx, new(big.Int).SetUint64(blob.BlockNumber))
+	if err != nil {
+		return nil, errors.New("failed to get block: " + err.Error())
+	}
+	if blob.BlockHash != (common.Hash{}) && block.Hash() != blob.BlockHash {
+		return nil, errNotCanonical
+	}
+	err = b.queries.AddChainBlock(ctx, dbgen.AddChainBlockParams{
+		BlockNumber: block.Number().Int64(),
+		BlockHash:   block.Hash().Bytes(),
+		ParentHash:  block.ParentHash().Bytes(),
+	})
+	if err != nil {
+		return nil, errors.New("failed to add chain block: " + err.Error())
+	}
+	return block, nil
+}
+
+// sourceTxHash returns the hash of the transaction in block that carries the
+// blob, unless the source already knows it
+func sourceTxHash(blob *SourceBlob, block *types.Block) (common.Hash, error) {
+	if blob.TxHash != nil {
+		return *blob.TxHash, nil
+	}
+	return blobTxHash(block, blob.VersionedHash)
+}
+
+// blobTxHash returns the hash of the transaction in block that carries the blob
+func blobTxHash(block *types.Block, versionedHash common.Hash) (common.Hash, error) {
+	for _, tx := range block.Transactions() {
+		for _, hash := range tx.BlobHashes() {
+			if hash == versionedHash {
+				return tx.Hash(), nil
+			}
+		}
+	}
+	return common.Hash{}, errors.New("blob " + versionedHash.Hex() + " is not part of block " + block.Number().String())
+}
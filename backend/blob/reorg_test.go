@@ -0,0 +1,126 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+func TestBlobTxHash(t *testing.T) {
+	hashes := []common.Hash{common.HexToHash("0x01aa"), common.HexToHash("0x01bb")}
+	blobTx := types.NewTx(&types.BlobTx{
+		ChainID:    uint256.NewInt(1),
+		Nonce:      1,
+		BlobFeeCap: uint256.NewInt(1),
+		BlobHashes: hashes,
+	})
+	txs := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 0}),
+		blobTx,
+	}
+	block := types.NewBlockWithHeader(&types.Header{}).WithBody(types.Body{Transactions: txs})
+
+	got, err := blobTxHash(block, hashes[1])
+	if err != nil {
+		t.Fatalf("blob tx hash error: %v", err)
+	}
+	if got != blobTx.Hash() {
+		t.Errorf("expected %s, got %s", blobTx.Hash().Hex(), got.Hex())
+	}
+	if _, err := blobTxHash(block, common.HexToHash("0x01cc")); err == nil {
+		t.Error("expected an error for a blob outside the block")
+	}
+}
+
+// fakeChain serves headers of a chain that forked off the original one
+type fakeChain struct {
+	byNumber map[int64]*types.Header
+	byHash   map[common.Hash]*types.Header
+}
+
+// newFakeChain builds a chain of length blocks, blocks from fork on get
+// different hashes than those of a chain built with another fork
+func newFakeChain(length, fork int64) *fakeChain {
+	chain := &fakeChain{byNumber: map[int64]*types.Header{}, byHash: map[common.Hash]*types.Header{}}
+	var parent common.Hash
+	for number := range length {
+		header := &types.Header{Number: big.NewInt(number), ParentHash: parent, Difficulty: big.NewInt(0)}
+		if number >= fork {
+			header.Extra = []byte("fork")
+		}
+		chain.byNumber[number] = header
+		chain.byHash[header.Hash()] = header
+		parent = header.Hash()
+	}
+	return chain
+}
+
+func (c *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := c.byNumber[number.Int64()]
+	if !ok {
+		return nil, errors.New("unknown block")
+	}
+	return header, nil
+}
+
+func (c *fakeChain) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	header, ok := c.byHash[hash]
+	if !ok {
+		return nil, errors.New("unknown block")
+	}
+	return header, nil
+}
+
+// recorded returns the rows the indexer would have stored for the blocks
+func (c *fakeChain) recorded(numbers ...int64) []dbgen.MessageChainBlock {
+	var blocks []dbgen.MessageChainBlock
+	for _, number := range numbers {
+		header := c.byNumber[number]
+		blocks = append(blocks, dbgen.MessageChainBlock{
+			BlockNumber: number,
+			BlockHash:   header.Hash().Bytes(),
+			ParentHash:  header.ParentHash.Bytes(),
+		})
+	}
+	return blocks
+}
+
+func TestFindForkPoint(t *testing.T) {
+	original := newFakeChain(40, 100)
+	tests := []struct {
+		name string
+		// block the canonical chain forked off the original one at
+		fork int64
+		// blocks recorded from the original chain in descending order, the
+		// first one is the cursor block
+		recorded  []int64
+		forkPoint int64
+	}{
+		{"no reorg", 100, []int64{30, 20, 10}, -1},
+		{"nothing recorded", 25, nil, -1},
+		// none of the orphaned blocks carried our blobs, the cursor block
+		// alone reveals the reorg and everything above block 20 is redone
+		{"reorg without our blobs", 25, []int64{30, 20, 10}, 21},
+		{"reorg below a recorded block", 15, []int64{30, 20, 10}, 11},
+		{"reorg below every recorded block", 5, []int64{30, 20, 10}, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonical := newFakeChain(40, tt.fork)
+			forkPoint, err := findForkPoint(context.Background(), canonical, original.recorded(tt.recorded...))
+			if err != nil {
+				t.Fatalf("fork point error: %v", err)
+			}
+			if forkPoint != tt.forkPoint {
+				t.Errorf("expected fork point %d, got %d", tt.forkPoint, forkPoint)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"proto-dankmessaging/backend/dependencies/config"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// SourceBlob is a blob together with the block it was included in
+type SourceBlob struct {
+	BlockNumber    uint64      `json:"block_number"`
+	BlockHash      common.Hash `json:"block_hash"`
+	BlockTimestamp time.Time   `json:"block_timestamp"`
+	// Index is the position of the blob within its block
+	Index         int         `json:"index"`
+	VersionedHash common.Hash `json:"versioned_hash"`
+	// Commitment and Proof are nil if the source does not provide them
+	Commitment *kzg4844.Commitment `json:"kzg_commitment,omitempty"`
+	Proof      *kzg4844.Proof      `json:"kzg_proof,omitempty"`
+	Data       *kzg4844.Blob       `json:"blob"`
+	// TxHash is nil if the source leaves finding the carrying transaction
+	// through the versioned hash to the indexer
+	TxHash *common.Hash `json:"tx_hash,omitempty"`
+
+	// err is set instead of Data if the blob was listed but could not be downloaded
+	err error
+}
+
+// BlobCursor points at a blob by its block number and index within the block
+type BlobCursor struct {
+	BlockNumber uint64
+	Index       int
+}
+
+// includes reports whether the blob is at or after the cursor
+func (c BlobCursor) includes(blob *SourceBlob) bool {
+	return blob.BlockNumber > c.BlockNumber || (blob.BlockNumber == c.BlockNumber && blob.Index >= c.Index)
+}
+
+// cursorAfter returns the cursor pointing right behind the blob
+func cursorAfter(blob *SourceBlob) BlobCursor {
+	return BlobCursor{BlockNumber: blob.BlockNumber, Index: blob.Index + 1}
+}
+
+// BlobSource provides the blobs included in the chain
+type BlobSource interface {
+	// Blobs returns up to roughly limit blobs at or after the cursor,
+	// ordered by block number and index within the block, and the cursor to
+	// continue from. The returned cursor equals the given one once the
+	// source has no further blobs.
+	Blobs(ctx context.Context, cursor BlobCursor, limit int) ([]*SourceBlob, BlobCursor, error)
+	// Blob fetches a single blob of the given block again
+	Blob(ctx context.Context, blockNumber uint64, versionedHash common.Hash) (*SourceBlob, error)
+}
+
+// findBlob returns the blob with the versioned hash or an error if it is missing
+func findBlob(blobs []*SourceBlob, versionedHash common.Hash) (*SourceBlob, error) {
+	for _, blob := range blobs {
+		if blob.VersionedHash == versionedHash {
+			return blob, nil
+		}
+	}
+	return nil, errors.New("blob " + versionedHash.Hex() + " not found")
+}
+
+// newBlobSource returns the configured source, relay is the address the
+// execution source always follows next to the configured relays
+func newBlobSource(c *config.Config, client *ethclient.Client, relay common.Address) (BlobSource, error) {
+	switch c.BlobSource {
+	case config.BlobSourceBeacon:
+		return NewBeaconSource(c.BeaconUrl, client), nil
+	case config.BlobSourceExecution:
+		relays := configuredRelays(c, relay)
+		var inbox common.Address
+		if c.InboxAddress != "" {
+			inbox = common.HexToAddress(c.InboxAddress)
+		}
+		chainID := new(big.Int).SetUint64(c.ChainId)
+		return NewExecutionSource(c.BeaconUrl, client, chainID, relays, inbox), nil
+	case config.BlobSourceInbox:
+		return NewInboxSource(c.BeaconUrl, client, common.HexToAddress(c.InboxAddress))
+	case config.BlobSourceBlobscan:
+		return NewBlobscanSource(c.BlobscanUrl), nil
+	case config.BlobSourceLocal:
+		return NewLocalSource(c.BlobDir), nil
+	case config.BlobSourceArchive:
+		return newBlobArchive(c)
+	default:
+		return nil, errors.New("unknown blob source " + string(c.BlobSource))
+	}
+}
+
+// configuredRelays returns the relay address next to the configured ones
+func configuredRelays(c *config.Config, relay common.Address) []common.Address {
+	relays := []common.Address{relay}
+	for _, address := range c.RelayAddresses {
+		relays = append(relays, common.HexToAddress(address))
+	}
+	return relays
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// httpGet fetches url and returns the body, any status but 200 is an error
+func httpGet(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("unexpected status " + strconv.Itoa(resp.StatusCode) + " from " + url)
+	}
+	return body, nil
+}
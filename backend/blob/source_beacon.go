@@ -0,0 +1,196 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// number of execution blocks a single call of BeaconSource.Blobs looks at
+const beaconBlocksPerCall = 100
+
+// BeaconSource reads blob sidecars from a consensus layer node, execution
+// blocks are mapped to beacon slots through their timestamp
+type BeaconSource struct {
+	url    string
+	client *ethclient.Client
+
+	// the spec is loaded on first use, mu guards it against concurrent backfill workers
+	mu             sync.Mutex
+	genesisTime    uint64
+	secondsPerSlot uint64
+}
+
+func NewBeaconSource(url string, client *ethclient.Client) *BeaconSource {
+	return &BeaconSource{
+		url:    strings.TrimSuffix(url, "/"),
+		client: client,
+	}
+}
+
+func (s *BeaconSource) Blobs(ctx context.Context, cursor BlobCursor, limit int) ([]*SourceBlob, BlobCursor, error) {
+	err := s.loadSpec(ctx)
+	if err != nil {
+		return nil, cursor, err
+	}
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, cursor, errors.New("failed to get block number: " + err.Error())
+	}
+	var blobs []*SourceBlob
+	number := cursor.BlockNumber
+	for ; number <= head && number < cursor.BlockNumber+beaconBlocksPerCall && len(blobs) < limit; number++ {
+		blockBlobs, err := s.blockBlobs(ctx, number)
+		if err != nil {
+			return nil, cursor, err
+		}
+		for _, blob := range blockBlobs {
+			if cursor.includes(blob) {
+				blobs = append(blobs, blob)
+			}
+		}
+	}
+	if number == cursor.BlockNumber {
+		return blobs, cursor, nil
+	}
+	return blobs, BlobCursor{BlockNumber: number}, nil
+}
+
+func (s *BeaconSource) Blob(ctx context.Context, blockNumber uint64, versionedHash common.Hash) (*SourceBlob, error) {
+	err := s.loadSpec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := s.blockBlobs(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return findBlob(blobs, versionedHash)
+}
+
+// blockBlobs returns the blobs of an execution block
+func (s *BeaconSource) blockBlobs(ctx context.Context, number uint64) ([]*SourceBlob, error) {
+	header, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, errors.New("failed to get header: " + err.Error())
+	}
+	if header.BlobGasUsed == nil || *header.BlobGasUsed == 0 {
+		return nil, nil
+	}
+	return s.headerBlobs(ctx, header, nil)
+}
+
+// headerBlobs returns the blobs of the block with the given header, limited
+// to the blob indices if any are given
+func (s *BeaconSource) headerBlobs(ctx context.Context, header *types.Header, indices []int) ([]*SourceBlob, error) {
+	slot := (header.Time - s.genesisTime) / s.secondsPerSlot
+	sidecars, err := s.sidecars(ctx, strconv.FormatUint(slot, 10), indices)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([]*SourceBlob, 0, len(sidecars))
+	for _, sidecar := range sidecars {
+		index, err := strconv.Atoi(sidecar.Index)
+		if err != nil {
+			return nil, errors.New("invalid sidecar index: " + err.Error())
+		}
+		blobs = append(blobs, &SourceBlob{
+			BlockNumber:    header.Number.Uint64(),
+			BlockHash:      header.Hash(),
+			BlockTimestamp: time.Unix(int64(header.Time), 0).UTC(),
+			Index:          index,
+			VersionedHash:  kzg4844.CalcBlobHashV1(sha256.New(), &sidecar.Commitment),
+			Commitment:     &sidecar.Commitment,
+			Proof:          &sidecar.Proof,
+			Data:           sidecar.Blob,
+		})
+	}
+	return blobs, nil
+}
+
+type beaconSidecar struct {
+	Index      string             `json:"index"`
+	Blob       *kzg4844.Blob      `json:"blob"`
+	Commitment kzg4844.Commitment `json:"kzg_commitment"`
+	Proof      kzg4844.Proof      `json:"kzg_proof"`
+}
+
+func (s *BeaconSource) sidecars(ctx context.Context, blockID string, indices []int) ([]beaconSidecar, error) {
+	url := s.url + "/eth/v1/beacon/blob_sidecars/" + blockID
+	if len(indices) > 0 {
+		query := make([]string, len(indices))
+		for i, index := range indices {
+			query[i] = strconv.Itoa(index)
+		}
+		url += "?indices=" + strings.Join(query, ",")
+	}
+	body, err := httpGet(ctx, url)
+	if err != nil {
+		return nil, errors.New("failed to get blob sidecars: " + err.Error())
+	}
+	var resp struct {
+		Data []beaconSidecar `json:"data"`
+	}
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return nil, errors.New("failed to unmarshal blob sidecars: " + err.Error())
+	}
+	return resp.Data, nil
+}
+
+// loadSpec fetches the genesis time and slot duration once
+func (s *BeaconSource) loadSpec(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.secondsPerSlot != 0 {
+		return nil
+	}
+	body, err := httpGet(ctx, s.url+"/eth/v1/beacon/genesis")
+	if err != nil {
+		return errors.New("failed to get beacon genesis: " + err.Error())
+	}
+	var genesis struct {
+		Data struct {
+			GenesisTime string `json:"genesis_time"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(body, &genesis)
+	if err != nil {
+		return errors.New("failed to unmarshal beacon genesis: " + err.Error())
+	}
+	body, err = httpGet(ctx, s.url+"/eth/v1/config/spec")
+	if err != nil {
+		return errors.New("failed to get beacon spec: " + err.Error())
+	}
+	var spec struct {
+		Data struct {
+			SecondsPerSlot string `json:"SECONDS_PER_SLOT"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(body, &spec)
+	if err != nil {
+		return errors.New("failed to unmarshal beacon spec: " + err.Error())
+	}
+	genesisTime, err := strconv.ParseUint(genesis.Data.GenesisTime, 10, 64)
+	if err != nil {
+		return errors.New("invalid genesis time: " + err.Error())
+	}
+	secondsPerSlot, err := strconv.ParseUint(spec.Data.SecondsPerSlot, 10, 64)
+	if err != nil || secondsPerSlot == 0 {
+		return errors.New("invalid seconds per slot " + spec.Data.SecondsPerSlot)
+	}
+	s.genesisTime = genesisTime
+	s.secondsPerSlot = secondsPerSlot
+	return nil
+}
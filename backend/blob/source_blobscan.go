@@ -0,0 +1,162 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/rs/zerolog/log"
+)
+
+// BlobscanSource lists blobs through the Blobscan explorer API
+type BlobscanSource struct {
+	url string
+}
+
+func NewBlobscanSource(url string) *BlobscanSource {
+	return &BlobscanSource{url: strings.TrimSuffix(url, "/")}
+}
+
+type blobscanBlob struct {
+	BlockNumber           uint64              `json:"blockNumber"`
+	BlockHash             common.Hash         `json:"blockHash"`
+	BlockTimestamp        time.Time           `json:"blockTimestamp"`
+	Index                 int                 `json:"index"`
+	VersionedHash         common.Hash         `json:"versionedHash"`
+	Commitment            *kzg4844.Commitment `json:"commitment"`
+	Proof                 *kzg4844.Proof      `json:"proof"`
+	DataStorageReferences []struct {
+		Storage string `json:"storage"`
+		URL     string `json:"url"`
+	} `json:"dataStorageReferences"`
+}
+
+func (s *BlobscanSource) Blobs(ctx context.Context, cursor BlobCursor, limit int) ([]*SourceBlob, BlobCursor, error) {
+	next := cursor
+	var blobs []*SourceBlob
+	// blobs of the cursor block in front of the cursor are skipped, so keep
+	// paging until blobs behind the cursor show up or the list ends
+	for page := 1; len(blobs) == 0; page++ {
+		blobList, err := s.list(ctx, cursor.BlockNumber, page, limit)
+		if err != nil {
+			return nil, cursor, err
+		}
+		log.Info().Int("blob_count", len(blobList)).Int("page", page).Msg("received blob list response")
+		for _, blob := range blobList {
+			sourceBlob := blob.sourceBlob()
+			if !cursor.includes(sourceBlob) {
+				continue
+			}
+			next = cursorAfter(sourceBlob)
+			sourceBlob.Data, sourceBlob.err = s.download(ctx, &blob)
+			blobs = append(blobs, sourceBlob)
+		}
+		if len(blobList) < limit || next != cursor {
+			break
+		}
+	}
+	return blobs, next, nil
+}
+
+func (s *BlobscanSource) Blob(ctx context.Context, blockNumber uint64, versionedHash common.Hash) (*SourceBlob, error) {
+	blobList, err := s.list(ctx, blockNumber, 1, blobsPerPage)
+	if err != nil {
+		return nil, err
+	}
+	for _, blob := range blobList {
+		if blob.BlockNumber != blockNumber || blob.VersionedHash != versionedHash {
+			continue
+		}
+		sourceBlob := blob.sourceBlob()
+		sourceBlob.Data, err = s.download(ctx, &blob)
+		if err != nil {
+			return nil, err
+		}
+		return sourceBlob, nil
+	}
+	return nil, errors.New("blob " + versionedHash.Hex() + " not found")
+}
+
+func (blob *blobscanBlob) sourceBlob() *SourceBlob {
+	return &SourceBlob{
+		BlockNumber:    blob.BlockNumber,
+		BlockHash:      blob.BlockHash,
+		BlockTimestamp: blob.BlockTimestamp,
+		Index:          blob.Index,
+		VersionedHash:  blob.VersionedHash,
+		Commitment:     blob.Commitment,
+		Proof:          blob.Proof,
+	}
+}
+
+// list returns a page of canonical blobs starting at fromBlock
+func (s *BlobscanSource) list(ctx context.Context, fromBlock uint64, page int, limit int) ([]blobscanBlob, error) {
+	blobListUrl := s.url + "/blobs?sort=asc&type=canonical" +
+		"&p=" + strconv.Itoa(page) +
+		"&ps=" + strconv.Itoa(limit) +
+		"&startBlock=" + strconv.FormatUint(fromBlock, 10)
+	body, err := httpGet(ctx, blobListUrl)
+	if err != nil {
+		return nil, errors.New("failed to get blob list: " + err.Error())
+	}
+	var blobList struct {
+		Blobs []blobscanBlob `json:"blobs"`
+	}
+	err = json.Unmarshal(body, &blobList)
+	if err != nil {
+		return nil, errors.New("failed to unmarshal blob list: " + err.Error())
+	}
+	return blobList.Blobs, nil
+}
+
+// download tries the storage references of the blob before falling back to
+// the data endpoint of the API
+func (s *BlobscanSource) download(ctx context.Context, blob *blobscanBlob) (*kzg4844.Blob, error) {
+	for _, ref := range blob.DataStorageReferences {
+		if ref.Storage != "google" {
+			continue
+		}
+		body, err := httpGet(ctx, ref.URL)
+		if err != nil {
+			log.Debug().Err(err).Str("url", ref.URL).Msg("failed to download blob from storage")
+			continue
+		}
+		data, err := parseBlobData(body)
+		if err != nil {
+			log.Debug().Err(err).Str("url", ref.URL).Msg("failed to parse blob from storage")
+			continue
+		}
+		return data, nil
+	}
+	body, err := httpGet(ctx, s.url+"/blobs/"+blob.VersionedHash.Hex()+"/data")
+	if err != nil {
+		return nil, errors.New("failed to get blob data: " + err.Error())
+	}
+	return parseBlobData(body)
+}
+
+// parseBlobData accepts raw blob bytes as well as hex, optionally as a JSON string
+func parseBlobData(body []byte) (*kzg4844.Blob, error) {
+	var blob kzg4844.Blob
+	if len(body) == len(blob) {
+		copy(blob[:], body)
+		return &blob, nil
+	}
+	text := string(bytes.Trim(bytes.TrimSpace(body), `"`))
+	data, err := hexutil.Decode(text)
+	if err != nil {
+		return nil, errors.New("failed to decode blob data: " + err.Error())
+	}
+	if len(data) != len(blob) {
+		return nil, errors.New("invalid blob size " + strconv.Itoa(len(data)))
+	}
+	copy(blob[:], data)
+	return &blob, nil
+}
@@ -0,0 +1,141 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rs/zerolog/log"
+)
+
+// ExecutionSource walks execution blocks and only fetches the sidecars of
+// blob transactions sent by a relay or to the inbox, blobs of other
+// transactions are never downloaded. Payloads the relays posted as calldata
+// come from the walked blocks as well.
+type ExecutionSource struct {
+	beacon *BeaconSource
+	client *ethclient.Client
+	filter *relayFilter
+}
+
+func NewExecutionSource(beaconUrl string, client *ethclient.Client, chainID *big.Int, relays []common.Address, inbox common.Address) *ExecutionSource {
+	return &ExecutionSource{
+		beacon: NewBeaconSource(beaconUrl, client),
+		client: client,
+		filter: newRelayFilter(chainID, relays, inbox),
+	}
+}
+
+func (s *ExecutionSource) Blobs(ctx context.Context, cursor BlobCursor, limit int) ([]*SourceBlob, BlobCursor, error) {
+	err := s.beacon.loadSpec(ctx)
+	if err != nil {
+		return nil, cursor, err
+	}
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, cursor, errors.New("failed to get block number: " + err.Error())
+	}
+	var blobs []*SourceBlob
+	number := cursor.BlockNumber
+	for ; number <= head && number < cursor.BlockNumber+beaconBlocksPerCall && len(blobs) < limit; number++ {
+		blockBlobs, err := s.blockBlobs(ctx, number)
+		if err != nil {
+			return nil, cursor, err
+		}
+		for _, blob := range blockBlobs {
+			if cursor.includes(blob) {
+				blobs = append(blobs, blob)
+			}
+		}
+	}
+	if number == cursor.BlockNumber {
+		return blobs, cursor, nil
+	}
+	return blobs, BlobCursor{BlockNumber: number}, nil
+}
+
+func (s *ExecutionSource) Blob(ctx context.Context, blockNumber uint64, versionedHash common.Hash) (*SourceBlob, error) {
+	err := s.beacon.loadSpec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := s.blockBlobs(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return findBlob(blobs, versionedHash)
+}
+
+// blockBlobs returns the blobs of the matching transactions of a block
+// followed by their calldata payloads
+func (s *ExecutionSource) blockBlobs(ctx context.Context, number uint64) ([]*SourceBlob, error) {
+	block, err := s.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, errors.New("failed to get block: " + err.Error())
+	}
+	var blobs []*SourceBlob
+	indices := s.blobIndices(block)
+	if len(indices) > 0 {
+		log.Debug().Uint64("block_number", number).Ints("indices", indices).Msg("found relay blobs")
+		blobs, err = s.beacon.headerBlobs(ctx, block.Header(), indices)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(blobs, calldataBlobs(block, s.filter)...), nil
+}
+
+// blobIndices returns the indices within the block of the blobs carried by
+// transactions of a relay or to the inbox
+func (s *ExecutionSource) blobIndices(block *types.Block) []int {
+	var indices []int
+	index := 0
+	for _, tx := range block.Transactions() {
+		hashes := tx.BlobHashes()
+		if len(hashes) == 0 {
+			continue
+		}
+		if s.filter.matches(tx) {
+			for i := range hashes {
+				indices = append(indices, index+i)
+			}
+		}
+		index += len(hashes)
+	}
+	return indices
+}
+
+// relayFilter matches the transactions sent by a relay or to the inbox
+type relayFilter struct {
+	signer types.Signer
+	relays map[common.Address]bool
+	// inbox is the zero address if no inbox is configured
+	inbox common.Address
+}
+
+func newRelayFilter(chainID *big.Int, relays []common.Address, inbox common.Address) *relayFilter {
+	relaySet := make(map[common.Address]bool, len(relays))
+	for _, relay := range relays {
+		relaySet[relay] = true
+	}
+	return &relayFilter{
+		signer: types.LatestSignerForChainID(chainID),
+		relays: relaySet,
+		inbox:  inbox,
+	}
+}
+
+func (f *relayFilter) matches(tx *types.Transaction) bool {
+	if f.inbox != (common.Address{}) && tx.To() != nil && *tx.To() == f.inbox {
+		return true
+	}
+	sender, err := types.Sender(f.signer, tx)
+	if err != nil {
+		log.Debug().Err(err).Str("tx_hash", tx.Hash().Hex()).Msg("failed to recover tx sender")
+		return false
+	}
+	return f.relays[sender]
+}
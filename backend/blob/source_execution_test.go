@@ -0,0 +1,62 @@
+package blob
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+func TestExecutionSourceBlobIndices(t *testing.T) {
+	chainID := big.NewInt(11155111)
+	signer := types.LatestSignerForChainID(chainID)
+	relayKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	inbox := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	other := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+
+	blobTx := func(key *ecdsa.PrivateKey, to common.Address, blobs int) *types.Transaction {
+		hashes := make([]common.Hash, blobs)
+		for i := range hashes {
+			hashes[i] = common.Hash{0x01, byte(i)}
+		}
+		tx, err := types.SignNewTx(key, signer, &types.BlobTx{
+			ChainID:    uint256.MustFromBig(chainID),
+			To:         to,
+			BlobHashes: hashes,
+		})
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		return tx
+	}
+	plainTx, err := types.SignNewTx(relayKey, signer, &types.DynamicFeeTx{ChainID: chainID, To: &other})
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+
+	txs := []*types.Transaction{
+		blobTx(otherKey, other, 2), // 0-1, foreign
+		plainTx,
+		blobTx(relayKey, other, 1), // 2, sent by the relay
+		blobTx(otherKey, inbox, 3), // 3-5, sent to the inbox
+		blobTx(otherKey, other, 1), // 6, foreign
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody(types.Body{Transactions: txs})
+
+	source := NewExecutionSource("", nil, chainID, []common.Address{crypto.PubkeyToAddress(relayKey.PublicKey)}, inbox)
+	indices := source.blobIndices(block)
+	if !slices.Equal(indices, []int{2, 3, 4, 5}) {
+		t.Errorf("expected indices [2 3 4 5], got %v", indices)
+	}
+
+	source = NewExecutionSource("", nil, chainID, nil, common.Address{})
+	if indices := source.blobIndices(block); len(indices) != 0 {
+		t.Errorf("expected no indices without relays or inbox, got %v", indices)
+	}
+}
@@ -0,0 +1,144 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"proto-dankmessaging/backend/blob/inbox"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rs/zerolog/log"
+)
+
+// number of blocks a single eth_getLogs call of InboxSource.Blobs spans
+const inboxBlocksPerCall = 1000
+
+// InboxSource finds blobs through the BlobAnnounced events of the inbox
+// contract and only fetches the sidecars of announced blobs
+type InboxSource struct {
+	beacon   *BeaconSource
+	client   *ethclient.Client
+	filterer *inbox.BlobInboxFilterer
+}
+
+func NewInboxSource(beaconUrl string, client *ethclient.Client, address common.Address) (*InboxSource, error) {
+	filterer, err := inbox.NewBlobInboxFilterer(address, client)
+	if err != nil {
+		return nil, errors.New("failed to bind inbox: " + err.Error())
+	}
+	return &InboxSource{
+		beacon:   NewBeaconSource(beaconUrl, client),
+		client:   client,
+		filterer: filterer,
+	}, nil
+}
+
+// Blobs returns the blobs announced in the next inboxBlocksPerCall blocks,
+// the limit is ignored as a block range is read at once
+func (s *InboxSource) Blobs(ctx context.Context, cursor BlobCursor, limit int) ([]*SourceBlob, BlobCursor, error) {
+	err := s.beacon.loadSpec(ctx)
+	if err != nil {
+		return nil, cursor, err
+	}
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, cursor, errors.New("failed to get block number: " + err.Error())
+	}
+	if cursor.BlockNumber > head {
+		return nil, cursor, nil
+	}
+	end := min(head, cursor.BlockNumber+inboxBlocksPerCall-1)
+	iter, err := s.filterer.FilterBlobAnnounced(&bind.FilterOpts{Start: cursor.BlockNumber, End: &end, Context: ctx}, nil, nil)
+	if err != nil {
+		return nil, cursor, errors.New("failed to filter blob announcements: " + err.Error())
+	}
+	defer iter.Close()
+	// logs come in chain order, group the announced hashes by block
+	var blocks []common.Hash
+	announced := make(map[common.Hash][]common.Hash)
+	for iter.Next() {
+		event := iter.Event
+		if event.Raw.Removed {
+			continue
+		}
+		if event.Version > payloadVersion {
+			log.Debug().Uint8("version", event.Version).Str("tx_hash", event.Raw.TxHash.Hex()).Msg("skipping blob of unknown version")
+			continue
+		}
+		blockHash := event.Raw.BlockHash
+		if _, ok := announced[blockHash]; !ok {
+			blocks = append(blocks, blockHash)
+		}
+		announced[blockHash] = append(announced[blockHash], event.VersionedHash)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, cursor, errors.New("failed to read blob announcements: " + err.Error())
+	}
+
+	var blobs []*SourceBlob
+	for _, blockHash := range blocks {
+		block, err := s.client.BlockByHash(ctx, blockHash)
+		if err != nil {
+			return nil, cursor, errors.New("failed to get block: " + err.Error())
+		}
+		blockBlobs, err := s.announcedBlobs(ctx, block, announced[blockHash])
+		if err != nil {
+			return nil, cursor, err
+		}
+		for _, blob := range blockBlobs {
+			if cursor.includes(blob) {
+				blobs = append(blobs, blob)
+			}
+		}
+	}
+	return blobs, BlobCursor{BlockNumber: end + 1}, nil
+}
+
+func (s *InboxSource) Blob(ctx context.Context, blockNumber uint64, versionedHash common.Hash) (*SourceBlob, error) {
+	err := s.beacon.loadSpec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	block, err := s.client.BlockByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return nil, errors.New("failed to get block: " + err.Error())
+	}
+	blobs, err := s.announcedBlobs(ctx, block, []common.Hash{versionedHash})
+	if err != nil {
+		return nil, err
+	}
+	return findBlob(blobs, versionedHash)
+}
+
+// announcedBlobs fetches the sidecars of the announced blobs of a block
+func (s *InboxSource) announcedBlobs(ctx context.Context, block *types.Block, hashes []common.Hash) ([]*SourceBlob, error) {
+	var indices []int
+	for _, hash := range hashes {
+		index, ok := blockBlobIndex(block, hash)
+		if !ok {
+			return nil, errors.New("blob " + hash.Hex() + " not found in block " + block.Number().String())
+		}
+		indices = append(indices, index)
+	}
+	slices.Sort(indices)
+	return s.beacon.headerBlobs(ctx, block.Header(), slices.Compact(indices))
+}
+
+// blockBlobIndex returns the index of the blob with the versioned hash
+// within the block
+func blockBlobIndex(block *types.Block, versionedHash common.Hash) (int, bool) {
+	index := 0
+	for _, tx := range block.Transactions() {
+		for _, hash := range tx.BlobHashes() {
+			if hash == versionedHash {
+				return index, true
+			}
+			index++
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,51 @@
+package blob
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBlockBlobIndex(t *testing.T) {
+	blobTx := func(hashes ...common.Hash) *types.Transaction {
+		return types.NewTx(&types.BlobTx{BlobHashes: hashes})
+	}
+	txs := []*types.Transaction{
+		blobTx(common.Hash{0x01, 0x01}, common.Hash{0x01, 0x02}),
+		types.NewTx(&types.DynamicFeeTx{}),
+		blobTx(common.Hash{0x01, 0x03}),
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody(types.Body{Transactions: txs})
+
+	for hash, expected := range map[common.Hash]int{
+		{0x01, 0x01}: 0,
+		{0x01, 0x02}: 1,
+		{0x01, 0x03}: 2,
+	} {
+		index, ok := blockBlobIndex(block, hash)
+		if !ok || index != expected {
+			t.Errorf("expected index %d for %s, got %d (found %v)", expected, hash.Hex(), index, ok)
+		}
+	}
+	if _, ok := blockBlobIndex(block, common.Hash{0x01, 0x04}); ok {
+		t.Error("expected missing blob not to be found")
+	}
+}
+
+func TestAnnounceCall(t *testing.T) {
+	data, err := announceCall()
+	if err != nil {
+		t.Fatalf("failed to build announce call: %v", err)
+	}
+	selector := crypto.Keccak256([]byte("announce(uint8)"))[:4]
+	if !bytes.Equal(data[:4], selector) {
+		t.Errorf("expected selector %x, got %x", selector, data[:4])
+	}
+	if len(data) != 36 || data[35] != payloadVersion {
+		t.Errorf("expected the payload version as argument, got %x", data[4:])
+	}
+}
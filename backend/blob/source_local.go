@@ -0,0 +1,132 @@
+package blob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// LocalSource reads blobs from a directory of JSON encoded SourceBlob files
+// named after their block number and index, as written by WriteLocalBlob.
+// It doubles as a BlobArchive on the local filesystem.
+type LocalSource struct {
+	dir string
+}
+
+func NewLocalSource(dir string) *LocalSource {
+	return &LocalSource{dir: dir}
+}
+
+// blobFileName names archived blobs so they sort by block number and index
+func blobFileName(blockNumber uint64, index int) string {
+	return fmt.Sprintf("%012d-%03d.json", blockNumber, index)
+}
+
+// blockFilePrefix is the common prefix of the file names of a block
+func blockFilePrefix(blockNumber uint64) string {
+	return fmt.Sprintf("%012d-", blockNumber)
+}
+
+// parseBlobFileName returns the position of the blob named by blobFileName
+func parseBlobFileName(name string) (BlobCursor, bool) {
+	var cursor BlobCursor
+	if !strings.HasSuffix(name, ".json") {
+		return cursor, false
+	}
+	_, err := fmt.Sscanf(name, "%d-%d.json", &cursor.BlockNumber, &cursor.Index)
+	return cursor, err == nil
+}
+
+// WriteLocalBlob stores the blob in dir so a LocalSource can read it
+func WriteLocalBlob(dir string, blob *SourceBlob) error {
+	data, err := json.Marshal(blob)
+	if err != nil {
+		return errors.New("failed to marshal blob: " + err.Error())
+	}
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return errors.New("failed to create blob directory: " + err.Error())
+	}
+	path := filepath.Join(dir, blobFileName(blob.BlockNumber, blob.Index))
+	// write to a temporary file first so readers never see partial blobs
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0o644)
+	if err != nil {
+		return errors.New("failed to write blob: " + err.Error())
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *LocalSource) Blobs(ctx context.Context, cursor BlobCursor, limit int) ([]*SourceBlob, BlobCursor, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, cursor, errors.New("failed to read blob directory: " + err.Error())
+	}
+	next := cursor
+	var blobs []*SourceBlob
+	// entries are sorted by name and therefore by block number and index
+	for _, entry := range entries {
+		name := entry.Name()
+		position, ok := parseBlobFileName(name)
+		if entry.IsDir() || !ok || !cursor.includes(&SourceBlob{BlockNumber: position.BlockNumber, Index: position.Index}) {
+			continue
+		}
+		if len(blobs) >= limit {
+			break
+		}
+		blob, err := readLocalBlob(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, cursor, err
+		}
+		blobs = append(blobs, blob)
+		next = cursorAfter(blob)
+	}
+	return blobs, next, nil
+}
+
+func (s *LocalSource) Blob(ctx context.Context, blockNumber uint64, versionedHash common.Hash) (*SourceBlob, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, blockFilePrefix(blockNumber)+"*.json"))
+	if err != nil {
+		return nil, errors.New("failed to list blobs: " + err.Error())
+	}
+	var blobs []*SourceBlob
+	for _, path := range paths {
+		blob, err := readLocalBlob(path)
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, blob)
+	}
+	return findBlob(blobs, versionedHash)
+}
+
+func (s *LocalSource) Put(ctx context.Context, blob *SourceBlob) error {
+	return WriteLocalBlob(s.dir, blob)
+}
+
+func (s *LocalSource) Remove(ctx context.Context, blob *SourceBlob) error {
+	err := os.Remove(filepath.Join(s.dir, blobFileName(blob.BlockNumber, blob.Index)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.New("failed to remove blob: " + err.Error())
+	}
+	return nil
+}
+
+func readLocalBlob(path string) (*SourceBlob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("failed to read blob: " + err.Error())
+	}
+	var blob SourceBlob
+	err = json.Unmarshal(data, &blob)
+	if err != nil {
+		return nil, errors.New("failed to unmarshal blob " + filepath.Base(path) + ": " + err.Error())
+	}
+	return &blob, nil
+}
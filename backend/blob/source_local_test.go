@@ -0,0 +1,102 @@
+package blob
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestLocalSource(t *testing.T) {
+	dir := t.TempDir()
+	for _, pos := range []struct {
+		block uint64
+		index int
+	}{{10, 0}, {10, 1}, {12, 0}, {15, 0}} {
+		err := WriteLocalBlob(dir, &SourceBlob{
+			BlockNumber:    pos.block,
+			BlockTimestamp: time.Unix(int64(pos.block), 0).UTC(),
+			Index:          pos.index,
+			Data:           new(kzg4844.Blob),
+		})
+		if err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+	}
+
+	source := NewLocalSource(dir)
+	// the cursor may point into the middle of a block
+	blobs, next, err := source.Blobs(context.Background(), BlobCursor{BlockNumber: 9}, 1)
+	if err != nil {
+		t.Fatalf("blobs error: %v", err)
+	}
+	if len(blobs) != 1 || next != (BlobCursor{BlockNumber: 10, Index: 1}) {
+		t.Fatalf("expected 1 blob and cursor 10/1, got %d and %v", len(blobs), next)
+	}
+
+	blobs, next, err = source.Blobs(context.Background(), next, 2)
+	if err != nil {
+		t.Fatalf("blobs error: %v", err)
+	}
+	if len(blobs) != 2 || next != (BlobCursor{BlockNumber: 12, Index: 1}) {
+		t.Fatalf("expected 2 blobs and cursor 12/1, got %d and %v", len(blobs), next)
+	}
+	if blobs[0].BlockNumber != 10 || blobs[0].Index != 1 {
+		t.Errorf("unexpected first blob at %d/%d", blobs[0].BlockNumber, blobs[0].Index)
+	}
+
+	blobs, next, err = source.Blobs(context.Background(), next, 10)
+	if err != nil {
+		t.Fatalf("blobs error: %v", err)
+	}
+	if len(blobs) != 1 || next != (BlobCursor{BlockNumber: 15, Index: 1}) {
+		t.Fatalf("expected 1 blob and cursor 15/1, got %d and %v", len(blobs), next)
+	}
+
+	// a caught up source returns the cursor unchanged
+	blobs, last, err := source.Blobs(context.Background(), next, 10)
+	if err != nil {
+		t.Fatalf("blobs error: %v", err)
+	}
+	if len(blobs) != 0 || last != next {
+		t.Fatalf("expected no blobs and an unchanged cursor, got %d and %v", len(blobs), last)
+	}
+}
+
+func TestLocalSourceArchive(t *testing.T) {
+	archive := NewLocalSource(t.TempDir())
+	data, err := EncodeDataToBlob([]byte("archived"))
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	blob := &SourceBlob{BlockNumber: 7, Index: 2, VersionedHash: common.HexToHash("0x01"), Data: data}
+	// storing a blob twice overwrites it
+	for range 2 {
+		if err := archive.Put(context.Background(), blob); err != nil {
+			t.Fatalf("put error: %v", err)
+		}
+	}
+
+	got, err := archive.Blob(context.Background(), 7, blob.VersionedHash)
+	if err != nil {
+		t.Fatalf("blob error: %v", err)
+	}
+	if got.Index != 2 || *got.Data != *data {
+		t.Errorf("archived blob does not match the stored one")
+	}
+	if _, err := archive.Blob(context.Background(), 7, common.HexToHash("0x02")); err == nil {
+		t.Error("expected an error for a missing blob")
+	}
+
+	// removing a blob twice is fine
+	for range 2 {
+		if err := archive.Remove(context.Background(), blob); err != nil {
+			t.Fatalf("remove error: %v", err)
+		}
+	}
+	if _, err := archive.Blob(context.Background(), 7, blob.VersionedHash); err == nil {
+		t.Error("expected the removed blob to be gone")
+	}
+}
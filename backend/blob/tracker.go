@@ -0,0 +1,342 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"proto-dankmessaging/backend/dependencies/config"
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog/log"
+)
+
+// pendingTx is a blob transaction that was sent but is not confirmed yet,
+// every replacement reuses the nonce and payloads
+type pendingTx struct {
+	nonce    uint64
+	payloads [][]byte
+	sidecar  *types.BlobTxSidecar
+	// carrier tells whether the payloads go into blobs or calldata, the
+	// sidecar is built either way for the versioned hashes
+	carrier dbgen.MessageTxCarrier
+	fees    *txFees
+	// hashes of every signed version, the latest last
+	hashes []common.Hash
+	sentAt time.Time
+}
+
+func pendingTxFromRow(row dbgen.MessageBlobTx) *pendingTx {
+	ptx := &pendingTx{
+		nonce:    uint64(row.Nonce),
+		payloads: row.Payloads,
+		carrier:  row.Carrier,
+		fees: &txFees{
+			GasTipCap:  big.NewInt(row.GasTipCap),
+			GasFeeCap:  big.NewInt(row.GasFeeCap),
+			BlobFeeCap: big.NewInt(row.BlobFeeCap),
+		},
+		sentAt: row.SentAt,
+	}
+	for _, hash := range row.TxHashes {
+		ptx.hashes = append(ptx.hashes, common.BytesToHash(hash))
+	}
+	return ptx
+}
+
+func (ptx *pendingTx) txHashes() [][]byte {
+	hashes := make([][]byte, len(ptx.hashes))
+	for i, hash := range ptx.hashes {
+		hashes[i] = hash.Bytes()
+	}
+	return hashes
+}
+
+func (ptx *pendingTx) addParams() (dbgen.AddBlobTxParams, error) {
+	gasTipCap, gasFeeCap, blobFeeCap, err := ptx.fees.storedCaps()
+	if err != nil {
+		return dbgen.AddBlobTxParams{}, err
+	}
+	return dbgen.AddBlobTxParams{
+		Nonce:      int64(ptx.nonce),
+		TxHashes:   ptx.txHashes(),
+		Payloads:   ptx.payloads,
+		GasTipCap:  gasTipCap,
+		GasFeeCap:  gasFeeCap,
+		BlobFeeCap: blobFeeCap,
+		SentAt:     ptx.sentAt,
+		Carrier:    ptx.carrier,
+	}, nil
+}
+
+// checkPendingTxs polls the receipts of the pending blob transactions, marks
+// the submissions of confirmed ones as confirmed and replaces stuck ones
+func (b *Blob) checkPendingTxs(ctx context.Context) error {
+	// submissions published without a chain are never finalized
+	if b.dep.Config.DABackend != config.DABackendLocal {
+		err := b.finalizeSubmissions(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	retention := time.Now().Add(-b.dep.Config.SubmissionRetention)
+	// the status of a submission and the metrics of its batch are kept for
+	// good, only its payload goes
+	err := b.queries.CompactConfirmedBlobSubmissions(ctx, retention)
+	if err != nil {
+		return errors.New("failed to compact confirmed blob submissions: " + err.Error())
+	}
+	rows, err := b.queries.GetBlobTxs(ctx)
+	if err != nil {
+		return errors.New("failed to get blob txs: " + err.Error())
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	head, err := b.client.BlockNumber(ctx)
+	if err != nil {
+		return errors.New("failed to get block number: " + err.Error())
+	}
+	nonce, err := b.client.NonceAt(ctx, b.key.Address, nil)
+	if err != nil {
+		return errors.New("failed to get nonce: " + err.Error())
+	}
+	var market *marketFees
+	for _, row := range rows {
+		ptx := pendingTxFromRow(row)
+		receipt, err := b.findReceipt(ctx, ptx)
+		if err != nil {
+			log.Error().Err(err).Uint64("nonce", ptx.nonce).Msg("failed to get blob transaction receipt")
+			continue
+		}
+		if receipt != nil {
+			if head < receipt.BlockNumber.Uint64()+b.dep.Config.ConfirmationDepth {
+				continue
+			}
+			if receipt.Status == types.ReceiptStatusFailed {
+				// a reverted inbox call announces nothing, so the indexer
+				// would never pick the payloads up
+				log.Warn().Str("tx_hash", receipt.TxHash.Hex()).Uint64("nonce", ptx.nonce).Msg("blob transaction reverted")
+				err = b.requeueTx(ctx, ptx.nonce)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			err = b.confirmTx(ctx, ptx, receipt)
+			if err != nil {
+				return err
+			}
+			err = b.archiveSubmittedTx(ctx, ptx, receipt)
+			if err != nil {
+				log.Error().Err(err).Uint64("nonce", ptx.nonce).Msg("failed to archive submitted blobs")
+			}
+			continue
+		}
+		if ptx.nonce < nonce {
+			// the nonce was used by a transaction we do not know about, the
+			// submissions go back into the queue
+			log.Warn().Uint64("nonce", ptx.nonce).Msg("blob transaction nonce was consumed by another transaction")
+			err = b.requeueTx(ctx, ptx.nonce)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if market == nil {
+			market, err = b.getMarketFees(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		reason := b.stuckReason(ctx, ptx, market)
+		if reason == "" {
+			continue
+		}
+		err = b.replaceTx(ctx, ptx, market, reason)
+		if err != nil {
+			log.Error().Err(err).Uint64("nonce", ptx.nonce).Msg("failed to replace blob transaction")
+		}
+	}
+	return nil
+}
+
+// findReceipt returns the receipt of whichever version of the transaction
+// was included, or nil if none was
+func (b *Blob) findReceipt(ctx context.Context, ptx *pendingTx) (*types.Receipt, error) {
+	for _, hash := range ptx.hashes {
+		receipt, err := b.client.TransactionReceipt(ctx, hash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return receipt, nil
+	}
+	return nil, nil
+}
+
+// stuckReason tells why a pending transaction needs to be replaced, an empty
+// string means it should be left alone
+func (b *Blob) stuckReason(ctx context.Context, ptx *pendingTx, market *marketFees) string {
+	blobUnderpriced := ptx.carrier == dbgen.MessageTxCarrierBlob && ptx.fees.BlobFeeCap.Cmp(market.BlobBaseFee) < 0
+	if ptx.fees.GasFeeCap.Cmp(market.BaseFee) < 0 || blobUnderpriced {
+		return "underpriced"
+	}
+	_, _, err := b.client.TransactionByHash(ctx, ptx.hashes[len(ptx.hashes)-1])
+	if errors.Is(err, ethereum.NotFound) {
+		return "dropped"
+	}
+	if time.Since(ptx.sentAt) > b.dep.Config.ReplaceAfter {
+		return "timed out"
+	}
+	return ""
+}
+
+// replaceTx re-signs the pending transaction with the same nonce and bumped
+// fees, the new hash is stored before sending so a crash can not lose track
+// of the version that gets included
+func (b *Blob) replaceTx(ctx context.Context, ptx *pendingTx, market *marketFees, reason string) error {
+	sidecar, err := buildSidecar(ptx.payloads)
+	if err != nil {
+		return err
+	}
+	ptx.sidecar = sidecar
+	oldCost, err := b.da.MaxCost(ctx, ptx, ptx.fees)
+	if err != nil {
+		return err
+	}
+	suggested := calcTxFees(b.dep.Config, market.BaseFee, market.BlobBaseFee, market.Tip)
+	fees := bumpFees(ptx.fees, suggested)
+	cost, err := b.da.MaxCost(ctx, ptx, fees)
+	if err != nil {
+		return err
+	}
+	additional := new(big.Int).Sub(cost, oldCost)
+	err = b.checkSpendingCaps(ctx, cost, additional)
+	if err != nil {
+		return err
+	}
+	gasTipCap, gasFeeCap, blobFeeCap, err := fees.storedCaps()
+	if err != nil {
+		return err
+	}
+	log.Warn().Uint64("nonce", ptx.nonce).Str("reason", reason).Str("tx_hash", ptx.hashes[len(ptx.hashes)-1].Hex()).Msg("replacing blob transaction")
+	signedTx, err := b.signBlobTx(ptx, fees)
+	if err != nil {
+		return err
+	}
+	err = b.inTx(ctx, func(qtx *dbgen.Queries) error {
+		err := qtx.UpdateBlobTx(ctx, dbgen.UpdateBlobTxParams{
+			Nonce:      int64(ptx.nonce),
+			TxHashes:   ptx.txHashes(),
+			GasTipCap:  gasTipCap,
+			GasFeeCap:  gasFeeCap,
+			BlobFeeCap: blobFeeCap,
+			SentAt:     ptx.sentAt,
+		})
+		if err != nil {
+			return errors.New("failed to update blob tx: " + err.Error())
+		}
+		nonce := int64(ptx.nonce)
+		err = qtx.SetBlobSubmissionsTxHash(ctx, dbgen.SetBlobSubmissionsTxHashParams{
+			Nonce:  &nonce,
+			TxHash: signedTx.Hash().Bytes(),
+		})
+		if err != nil {
+			return errors.New("failed to set blob submissions tx hash: " + err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return b.sendBlobTx(ctx, signedTx, additional)
+}
+
+// confirmTx marks the submissions of a transaction that is buried deep
+// enough as confirmed and stops tracking it
+func (b *Blob) confirmTx(ctx context.Context, ptx *pendingTx, receipt *types.Receipt) error {
+	err := b.inTx(ctx, func(qtx *dbgen.Queries) error {
+		nonce := int64(ptx.nonce)
+		blockNumber := receipt.BlockNumber.Int64()
+		err := qtx.ConfirmBlobSubmissions(ctx, dbgen.ConfirmBlobSubmissionsParams{
+			Nonce:       &nonce,
+			TxHash:      receipt.TxHash.Bytes(),
+			BlockNumber: &blockNumber,
+		})
+		if err != nil {
+			return errors.New("failed to confirm blob submissions: " + err.Error())
+		}
+		err = qtx.RemoveBlobTx(ctx, nonce)
+		if err != nil {
+			return errors.New("failed to remove blob tx: " + err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Info().
+		Str("tx_hash", receipt.TxHash.Hex()).
+		Uint64("block_number", receipt.BlockNumber.Uint64()).
+		Uint64("nonce", ptx.nonce).
+		Msg("blob transaction confirmed")
+	return nil
+}
+
+// finalizeSubmissions marks confirmed submissions in finalized blocks as finalized
+func (b *Blob) finalizeSubmissions(ctx context.Context) error {
+	finalized, err := b.client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil {
+		return errors.New("failed to get finalized header: " + err.Error())
+	}
+	blockNumber := finalized.Number.Int64()
+	err = b.queries.FinalizeBlobSubmissions(ctx, &blockNumber)
+	if err != nil {
+		return errors.New("failed to finalize blob submissions: " + err.Error())
+	}
+	return nil
+}
+
+// requeueTx puts the submissions of a transaction that will never be
+// included back into the queue
+func (b *Blob) requeueTx(ctx context.Context, nonce uint64) error {
+	return b.inTx(ctx, func(qtx *dbgen.Queries) error {
+		n := int64(nonce)
+		// messages have to be requeued first, they are found through the
+		// nonce of their submission
+		err := qtx.RequeueMessages(ctx, &n)
+		if err != nil {
+			return errors.New("failed to requeue messages: " + err.Error())
+		}
+		err = qtx.RequeueBlobSubmissions(ctx, &n)
+		if err != nil {
+			return errors.New("failed to requeue blob submissions: " + err.Error())
+		}
+		err = qtx.RemoveBlobTx(ctx, n)
+		if err != nil {
+			return errors.New("failed to remove blob tx: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// inTx runs fn inside a database transaction
+func (b *Blob) inTx(ctx context.Context, fn func(qtx *dbgen.Queries) error) error {
+	dbTx, err := b.dep.DB.Pool().Begin(ctx)
+	if err != nil {
+		return errors.New("failed to begin transaction: " + err.Error())
+	}
+	defer dbTx.Rollback(ctx)
+	err = fn(b.queries.WithTx(dbTx))
+	if err != nil {
+		return err
+	}
+	return dbTx.Commit(ctx)
+}
@@ -1,147 +1,289 @@
 package blob
 
 import (
-	"bytes"
 	"context"
-	"encoding/hex"
-	"encoding/json"
 	"errors"
-	"io"
-	"net/http"
+	"math"
+	"math/big"
+	"proto-dankmessaging/backend/dependencies/config"
 	"proto-dankmessaging/backend/dependencies/queries/dbgen"
-	"strconv"
+	"slices"
 	"time"
 
-	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/rs/zerolog/log"
-	"google.golang.org/protobuf/proto"
 )
 
-func (b *Blob) updateBlob() error {
-	blockHeight, err := b.queries.GetBlobUpdate(context.Background())
+// number of blobs requested from the blob source per page
+const blobsPerPage = 50
+
+// updateBlob walks the blob source page by page from the stored cursor until
+// it is caught up, the cursor is stored after every page
+func (b *Blob) updateBlob(ctx context.Context) error {
+	// blobs of the local backend are not part of any chain
+	if b.dep.Config.DABackend != config.DABackendLocal {
+		err := b.updateFinalized(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to update finalized block")
+		}
+		err = b.handleReorg(ctx)
+		if err != nil {
+			return errors.New("failed to handle reorg: " + err.Error())
+		}
+	}
+	update, err := b.queries.GetBlobUpdate(ctx)
 	if err != nil {
 		return errors.New("failed to get blob update: " + err.Error())
 	}
-	log.Info().Int64("block_height", blockHeight).Msg("updating blob")
+	cursor := BlobCursor{BlockNumber: uint64(update.BlockHeight), Index: int(update.BlobIndex)}
+	log.Info().Uint64("block_height", cursor.BlockNumber).Int("blob_index", cursor.Index).Msg("updating blob")
 
-	blobListUrl := "https://api.sepolia.blobscan.com/blobs?ps=50&sort=asc&type=canonical&startBlock=" + strconv.FormatInt(blockHeight, 10)
-	resp, err := http.Get(blobListUrl)
-	if err != nil {
-		return errors.New("failed to get blob list: " + err.Error())
-	}
-	defer resp.Body.Close()
-	var blobList struct {
-		Blobs []struct {
-			BlockNumber           uint64    `json:"blockNumber"`
-			BlockTimestamp        time.Time `json:"blockTimestamp"`
-			VersionedHash         string    `json:"versionedHash"`
-			DataStorageReferences []struct {
-				Storage string `json:"storage"`
-				URL     string `json:"url"`
-			} `json:"dataStorageReferences"`
-		} `json:"blobs"`
-	}
-	err = json.NewDecoder(resp.Body).Decode(&blobList)
-	if err != nil {
-		return errors.New("failed to unmarshal blob list: " + err.Error())
-	}
-	log.Info().Int("blob_count", len(blobList.Blobs)).Msg("received blob list response")
-	for _, blob := range blobList.Blobs {
-		if blob.BlockNumber > uint64(blockHeight) {
-			blockHeight = int64(blob.BlockNumber)
-		}
-		var rawBlobData []byte
-		for _, dataStorageReference := range blob.DataStorageReferences {
-			if dataStorageReference.Storage == "google" {
-				rawBlobData, err = b.downloadBlob(dataStorageReference.URL)
-				if err != nil {
-					// log.Error().Err(err).Msg("failed to download blob")
-					continue
-				}
-			}
+	for ctx.Err() == nil {
+		next, err := b.updatePage(ctx, cursor, math.MaxUint64)
+		if err != nil {
+			return err
 		}
-		if rawBlobData == nil {
-			rawBlobData, err = b.downloadBlobWithoutGoogle(blob.VersionedHash)
+		if b.dep.Config.DABackend != config.DABackendLocal {
+			err = b.recordCursorBlock(ctx, next)
 			if err != nil {
-				// log.Error().Err(err).Msg("failed to download blob without google")
-				continue
+				// the source may run ahead of the node
+				log.Warn().Err(err).Uint64("block_number", next.BlockNumber).Msg("failed to record cursor block")
 			}
 		}
-		var kzgBlobContent kzg4844.Blob
-		copy(kzgBlobContent[:], rawBlobData)
-		blobData, err := DecodeBlobToData(&kzgBlobContent)
+		err = b.queries.UpdateBlobUpdate(ctx, dbgen.UpdateBlobUpdateParams{
+			BlockHeight: int64(next.BlockNumber),
+			BlobIndex:   int32(next.Index),
+		})
 		if err != nil {
-			return errors.New("failed to decode blob: " + err.Error())
+			return errors.New("failed to set blob update: " + err.Error())
+		}
+		if next == cursor {
+			break
+		}
+		cursor = next
+	}
+	return nil
+}
+
+// updatePage ingests one page of blobs in front of the end block and returns
+// the cursor to continue from, which never passes the end block
+func (b *Blob) updatePage(ctx context.Context, cursor BlobCursor, end uint64) (BlobCursor, error) {
+	blobs, next, err := b.source.Blobs(ctx, cursor, blobsPerPage)
+	if err != nil {
+		return cursor, errors.New("failed to get blobs: " + err.Error())
+	}
+	if next.BlockNumber >= end {
+		next = BlobCursor{BlockNumber: end}
+	}
+	var block *types.Block
+	for _, blob := range blobs {
+		if blob.BlockNumber >= end {
+			break
 		}
-		// if blobData starts with blobMsgMagicBytes, then it is a valid blob
-		if len(blobData) < 100 {
+		blobContent, err := checkBlob(blob)
+		if err != nil {
+			if blob.err != nil && !b.isRelayBlob(ctx, blob) {
+				// downloads of foreign blobs are not worth retrying
+				log.Debug().Err(err).Str("versioned_hash", blob.VersionedHash.Hex()).Msg("failed to download foreign blob")
+				continue
+			}
+			b.addFailedBlob(ctx, blob, err)
 			continue
 		}
-		if !bytes.Equal(blobData[:len(blobMsgMagicBytes)], blobMsgMagicBytes) {
+		if blobContent == nil {
 			continue
 		}
-		log.Info().Bytes("blob_data_first_bytes", blobData[:10]).Msg("blob data has magic bytes")
-		var blobContent BlobContent
-		err = proto.Unmarshal(blobData[len(blobMsgMagicBytes):], &blobContent)
-		if err != nil {
-			log.Error().Err(err).Msg("failed to unmarshal blob")
+		if block == nil || block.NumberU64() != blob.BlockNumber {
+			block, err = b.recordBlock(ctx, blob)
+			if errors.Is(err, errNotCanonical) {
+				// blobs of orphaned blocks, e.g. from an archive, never
+				// become canonical, a reorg of the node rewinds the cursor
+				log.Warn().Str("versioned_hash", blob.VersionedHash.Hex()).Uint64("block_number", blob.BlockNumber).Msg("skipped blob of orphaned block")
+				block = nil
+				continue
+			}
+			if err != nil {
+				// the source may lag behind the node, retry from this blob
+				log.Warn().Err(err).Uint64("block_number", blob.BlockNumber).Msg("failed to record block")
+				return BlobCursor{BlockNumber: blob.BlockNumber, Index: blob.Index}, nil
+			}
+		}
+		if !b.blockCarriesBlob(blob, block) {
 			continue
 		}
-		err = b.addBlobToDB(&blobContent, blob.BlockTimestamp)
+		err = b.addBlobToDB(ctx, blob, blobContent, block)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to add blob to db")
+			b.addFailedBlob(ctx, blob, err)
 			continue
 		}
+		b.archiveBlob(ctx, blob)
 	}
-	err = b.queries.UpdateBlobUpdate(context.Background(), blockHeight)
+	return next, nil
+}
+
+// isRelayBlob reports whether a relay or the inbox posted the blob, blobs
+// that can not be looked up count as ours so their messages are not lost
+func (b *Blob) isRelayBlob(ctx context.Context, blob *SourceBlob) bool {
+	block, err := b.client.BlockByNumber(ctx, new(big.Int).SetUint64(blob.BlockNumber))
 	if err != nil {
-		return errors.New("failed to set blob update: " + err.Error())
+		log.Warn().Err(err).Uint64("block_number", blob.BlockNumber).Msg("failed to get block of blob")
+		return true
 	}
-	b.blockHeight = blockHeight
-	return nil
+	for _, tx := range block.Transactions() {
+		if slices.Contains(tx.BlobHashes(), blob.VersionedHash) {
+			return b.relays.matches(tx)
+		}
+	}
+	return true
 }
 
-func (b *Blob) downloadBlob(url string) ([]byte, error) {
-	return nil, errors.New("not implemented")
-	resp, err := http.Get(url)
+// checkBlob makes sure the blob was downloaded and matches its versioned
+// hash before decoding it, blobs that are not ours return nil without an
+// error. So do blobs failing verification, a corrupt or forged blob never
+// gets better, so there is no point in retrying it.
+func checkBlob(blob *SourceBlob) (*BlobContent, error) {
+	if blob.err != nil {
+		return nil, errors.New("failed to download blob: " + blob.err.Error())
+	}
+	err := blob.Verify()
 	if err != nil {
-		return nil, errors.New("failed to get blob: " + err.Error())
+		log.Warn().Err(err).
+			Str("versioned_hash", blob.VersionedHash.Hex()).
+			Uint64("block_number", blob.BlockNumber).
+			Msg("rejected blob")
+		return nil, nil
 	}
-	defer resp.Body.Close()
-	blobData, err := io.ReadAll(resp.Body)
+	return decodeBlob(blob)
+}
+
+// blockCarriesBlob rejects blobs their block does not carry, a source could
+// otherwise pass a forged blob under a versioned hash of its own making. Like
+// blobs failing verification they are dropped for good.
+func (b *Blob) blockCarriesBlob(blob *SourceBlob, block *types.Block) bool {
+	// blocks of the local backend have no transactions to check against
+	if b.dep.Config.DABackend == config.DABackendLocal {
+		return true
+	}
+	err := blob.VerifyBlock(block)
 	if err != nil {
-		return nil, errors.New("failed to read blob: " + err.Error())
+		log.Warn().Err(err).
+			Str("versioned_hash", blob.VersionedHash.Hex()).
+			Uint64("block_number", blob.BlockNumber).
+			Msg("rejected blob")
+		return false
 	}
-	return blobData, nil
+	return true
 }
 
-func (b *Blob) downloadBlobWithoutGoogle(id string) ([]byte, error) {
-	dataUrl := "https://api.sepolia.blobscan.com/blobs/" + id + "/data"
-	resp, err := http.Get(dataUrl)
+// decodeBlob decodes the messages of a blob, blobs that are not ours
+// return nil without an error
+func decodeBlob(blob *SourceBlob) (*BlobContent, error) {
+	blobData, err := DecodeBlobToData(blob.Data)
 	if err != nil {
-		return nil, errors.New("failed to get blob data: " + err.Error())
+		// blobs of other protocols do not have to follow our codec
+		log.Debug().Err(err).Str("versioned_hash", blob.VersionedHash.Hex()).Msg("failed to decode blob")
+		return nil, nil
+	}
+	// if blobData starts with blobMsgMagicBytes, then it is a valid blob
+	if !isPayload(blobData) {
+		return nil, nil
 	}
-	defer resp.Body.Close()
-	blobData, err := io.ReadAll(resp.Body)
-	blobData = blobData[3 : len(blobData)-1]
-	hexBlobData, err := hex.DecodeString(string(blobData))
+	log.Info().Int("blob_data_size", len(blobData)).Msg("blob data has magic bytes")
+	blobContent, err := decodePayload(blobData)
 	if err != nil {
-		return nil, errors.New("failed to decode blob data: " + err.Error())
+		return nil, errors.New("failed to unmarshal blob: " + err.Error())
+	}
+	return blobContent, nil
+}
+
+// addBlobToDB stores the messages and keys of a blob, blobs are only
+// ingested once per versioned hash
+func (b *Blob) addBlobToDB(ctx context.Context, blob *SourceBlob, blobContent *BlobContent, block *types.Block) error {
+	// blobs of the local backend are not carried by a transaction
+	var txHash []byte
+	if b.dep.Config.DABackend != config.DABackendLocal {
+		hash, err := sourceTxHash(blob, block)
+		if err != nil {
+			return err
+		}
+		txHash = hash.Bytes()
 	}
-	return hexBlobData, nil
+	return b.inTx(ctx, func(qtx *dbgen.Queries) error {
+		return ingestBlob(ctx, qtx, blob, blobContent, block, txHash)
+	})
+}
+
+// ingestQuerier is the part of the queries ingesting a blob needs
+type ingestQuerier interface {
+	AddIngestedBlob(ctx context.Context, arg dbgen.AddIngestedBlobParams) (int64, error)
+	AddChainPubkey(ctx context.Context, arg dbgen.AddChainPubkeyParams) error
+	ConfirmMessage(ctx context.Context, arg dbgen.ConfirmMessageParams) (int64, error)
+	AddMessage(ctx context.Context, arg dbgen.AddMessageParams) (dbgen.MessageBlob, error)
 }
 
-func (b *Blob) addBlobToDB(blobContent *BlobContent, submitTime time.Time) error {
-	for _, message := range blobContent.Messages {
-		_, err := b.queries.AddMessage(
-			context.Background(),
-			dbgen.AddMessageParams{
-				Index:           message.SearchIndex,
-				Message:         message.Message,
-				SubmitTime:      submitTime,
-				NeedsSubmission: false,
-			},
-		)
+// ingestBlob stores the messages and keys of a blob found in block
+func ingestBlob(ctx context.Context, qtx ingestQuerier, blob *SourceBlob, blobContent *BlobContent, block *types.Block, txHash []byte) error {
+	blockNumber := block.Number().Int64()
+	blockTime := time.Unix(int64(block.Time()), 0).UTC()
+	blobIndex := int32(blob.Index)
+	added, err := qtx.AddIngestedBlob(ctx, dbgen.AddIngestedBlobParams{
+		VersionedHash: blob.VersionedHash.Bytes(),
+		BlockNumber:   blockNumber,
+		BlockHash:     block.Hash().Bytes(),
+		BlobIndex:     int32(blob.Index),
+	})
+	if err != nil {
+		return errors.New("failed to add ingested blob: " + err.Error())
+	}
+	if added == 0 {
+		log.Debug().Str("versioned_hash", blob.VersionedHash.Hex()).Msg("blob already ingested")
+		return nil
+	}
+	for i, message := range blobContent.Messages {
+		// keys already known keep their timestamp so clients polling /keys
+		// do not see them again
+		if len(message.EphemeralPubkey) > 0 {
+			err := qtx.AddChainPubkey(ctx, dbgen.AddChainPubkeyParams{
+				Pubkey:      message.EphemeralPubkey,
+				SubmitTime:  blockTime,
+				BlockNumber: &blockNumber,
+			})
+			if err != nil {
+				return errors.New("failed to add pubkey to db: " + err.Error())
+			}
+		}
+		// a message posted to this relay is confirmed in place, every
+		// other message is added next to the ones with the same index
+		confirmed, err := qtx.ConfirmMessage(ctx, dbgen.ConfirmMessageParams{
+			SubmitTime:    blockTime,
+			BlockNumber:   &blockNumber,
+			BlockHash:     block.Hash().Bytes(),
+			TxHash:        txHash,
+			VersionedHash: blob.VersionedHash.Bytes(),
+			BlobIndex:     &blobIndex,
+			BlobPosition:  ptr(int32(i)),
+			Index:         message.SearchIndex,
+			Message:       message.Message,
+		})
+		if err != nil {
+			return errors.New("failed to confirm message: " + err.Error())
+		}
+		if confirmed > 0 {
+			log.Info().Interface("message", message).Msg("confirmed message in db")
+			continue
+		}
+		_, err = qtx.AddMessage(ctx, dbgen.AddMessageParams{
+			Index:         message.SearchIndex,
+			Message:       message.Message,
+			SubmitTime:    blockTime,
+			State:         dbgen.MessageBlobStateOnChain,
+			BlockNumber:   &blockNumber,
+			BlockHash:     block.Hash().Bytes(),
+			TxHash:        txHash,
+			VersionedHash: blob.VersionedHash.Bytes(),
+			BlobIndex:     &blobIndex,
+			BlobPosition:  ptr(int32(i)),
+		})
 		if err != nil {
 			return errors.New("failed to add message to db: " + err.Error())
 		}
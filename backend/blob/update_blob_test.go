@@ -0,0 +1,96 @@
+package blob
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"proto-dankmessaging/backend/dependencies/queries/dbgen"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeIngestQueries keeps what ingestBlob stores in memory, the pubkey upsert
+// mirrors AddChainPubkey
+type fakeIngestQueries struct {
+	ingested map[common.Hash]bool
+	pubkeys  map[string]dbgen.MessagePubkey
+	messages []dbgen.AddMessageParams
+}
+
+func newFakeIngestQueries() *fakeIngestQueries {
+	return &fakeIngestQueries{ingested: map[common.Hash]bool{}, pubkeys: map[string]dbgen.MessagePubkey{}}
+}
+
+func (q *fakeIngestQueries) AddIngestedBlob(ctx context.Context, arg dbgen.AddIngestedBlobParams) (int64, error) {
+	hash := common.BytesToHash(arg.VersionedHash)
+	if q.ingested[hash] {
+		return 0, nil
+	}
+	q.ingested[hash] = true
+	return 1, nil
+}
+
+func (q *fakeIngestQueries) AddChainPubkey(ctx context.Context, arg dbgen.AddChainPubkeyParams) error {
+	pubkey, ok := q.pubkeys[string(arg.Pubkey)]
+	if !ok {
+		q.pubkeys[string(arg.Pubkey)] = dbgen.MessagePubkey{Pubkey: arg.Pubkey, SubmitTime: arg.SubmitTime, BlockNumber: arg.BlockNumber}
+		return nil
+	}
+	if pubkey.BlockNumber == nil {
+		pubkey.BlockNumber = arg.BlockNumber
+	}
+	q.pubkeys[string(arg.Pubkey)] = pubkey
+	return nil
+}
+
+func (q *fakeIngestQueries) ConfirmMessage(ctx context.Context, arg dbgen.ConfirmMessageParams) (int64, error) {
+	return 0, nil
+}
+
+func (q *fakeIngestQueries) AddMessage(ctx context.Context, arg dbgen.AddMessageParams) (dbgen.MessageBlob, error) {
+	q.messages = append(q.messages, arg)
+	return dbgen.MessageBlob{}, nil
+}
+
+func TestIngestBlobPubkeys(t *testing.T) {
+	q := newFakeIngestQueries()
+	posted := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// a key posted to this relay is known before its blob lands
+	q.pubkeys["known"] = dbgen.MessagePubkey{Pubkey: []byte("known"), SubmitTime: posted}
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(42), Time: uint64(posted.Add(time.Hour).Unix())})
+	blob := &SourceBlob{BlockNumber: 42, VersionedHash: common.HexToHash("0x01aa")}
+	content := &BlobContent{Messages: []*Message{
+		{EphemeralPubkey: []byte("known"), SearchIndex: []byte{0x01}, Message: []byte("a")},
+		{EphemeralPubkey: []byte("new"), SearchIndex: []byte{0x02}, Message: []byte("b")},
+		{SearchIndex: []byte{0x03}, Message: []byte("c")},
+	}}
+	for range 2 {
+		err := ingestBlob(context.Background(), q, blob, content, block, nil)
+		if err != nil {
+			t.Fatalf("ingest error: %v", err)
+		}
+	}
+
+	if len(q.pubkeys) != 2 {
+		t.Fatalf("expected 2 pubkeys, got %d", len(q.pubkeys))
+	}
+	known := q.pubkeys["known"]
+	if !known.SubmitTime.Equal(posted) {
+		t.Errorf("expected the known key to keep its submit time, got %v", known.SubmitTime)
+	}
+	if known.BlockNumber == nil || *known.BlockNumber != 42 {
+		t.Errorf("expected the known key to get block 42, got %v", known.BlockNumber)
+	}
+	found := q.pubkeys["new"]
+	if !found.SubmitTime.Equal(posted.Add(time.Hour)) || found.BlockNumber == nil || *found.BlockNumber != 42 {
+		t.Errorf("expected the new key at the block time of block 42, got %v and %v", found.SubmitTime, found.BlockNumber)
+	}
+	// the second ingestion of the same blob adds nothing
+	if len(q.messages) != 3 {
+		t.Errorf("expected 3 messages, got %d", len(q.messages))
+	}
+}
@@ -0,0 +1,64 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"errors"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// Verify checks that the blob data matches the versioned hash it was
+// advertised under, so a blob source cannot inject forged blobs. The
+// commitment is always recomputed from the data, the proof is only checked
+// if the source provided one.
+func (s *SourceBlob) Verify() error {
+	if s.Data == nil {
+		return errors.New("blob has no data")
+	}
+	commitment, err := kzg4844.BlobToCommitment(s.Data)
+	if err != nil {
+		return errors.New("failed to compute blob commitment: " + err.Error())
+	}
+	if s.Commitment != nil && *s.Commitment != commitment {
+		return errors.New("blob commitment mismatch")
+	}
+	if kzg4844.CalcBlobHashV1(sha256.New(), &commitment) != s.VersionedHash {
+		return errors.New("blob versioned hash mismatch")
+	}
+	if s.Proof != nil {
+		err = kzg4844.VerifyBlobProof(s.Data, commitment, *s.Proof)
+		if err != nil {
+			return errors.New("invalid blob proof: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// VerifyBlock checks that the block carries the blob, Verify alone only ties
+// the data to the versioned hash the source advertised. Blobs have to be
+// listed by a transaction of the block, calldata payloads have to be the
+// calldata of their transaction.
+func (s *SourceBlob) VerifyBlock(block *types.Block) error {
+	if s.Index >= calldataIndexOffset {
+		if s.TxHash == nil {
+			return errors.New("calldata blob has no transaction")
+		}
+		tx := block.Transaction(*s.TxHash)
+		if tx == nil {
+			return errors.New("calldata transaction is not part of the block")
+		}
+		data, err := EncodeDataToBlob(tx.Data())
+		if err != nil || s.Data == nil || *data != *s.Data {
+			return errors.New("blob does not match the calldata of its transaction")
+		}
+		return nil
+	}
+	for _, tx := range block.Transactions() {
+		if slices.Contains(tx.BlobHashes(), s.VersionedHash) && (s.TxHash == nil || *s.TxHash == tx.Hash()) {
+			return nil
+		}
+	}
+	return errors.New("blob versioned hash is not part of the block")
+}
@@ -0,0 +1,108 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestSourceBlobVerify(t *testing.T) {
+	data, err := EncodeDataToBlob([]byte("hello blob"))
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	commitment, err := kzg4844.BlobToCommitment(data)
+	if err != nil {
+		t.Fatalf("commitment error: %v", err)
+	}
+	proof, err := kzg4844.ComputeBlobProof(data, commitment)
+	if err != nil {
+		t.Fatalf("proof error: %v", err)
+	}
+	valid := func() *SourceBlob {
+		blob := *data
+		return &SourceBlob{
+			VersionedHash: kzg4844.CalcBlobHashV1(sha256.New(), &commitment),
+			Commitment:    &commitment,
+			Proof:         &proof,
+			Data:          &blob,
+		}
+	}
+
+	if err := valid().Verify(); err != nil {
+		t.Fatalf("expected valid blob, got %v", err)
+	}
+	withoutProof := valid()
+	withoutProof.Commitment, withoutProof.Proof = nil, nil
+	if err := withoutProof.Verify(); err != nil {
+		t.Fatalf("expected valid blob without proof, got %v", err)
+	}
+
+	forgedData := valid()
+	forgedData.Data[100] ^= 0x01
+	if forgedData.Verify() == nil {
+		t.Error("expected forged data to be rejected")
+	}
+	forgedData.Commitment = nil
+	if forgedData.Verify() == nil {
+		t.Error("expected forged data without commitment to be rejected")
+	}
+	forgedHash := valid()
+	forgedHash.VersionedHash[31] ^= 0x01
+	if forgedHash.Verify() == nil {
+		t.Error("expected forged versioned hash to be rejected")
+	}
+	forgedProof := valid()
+	forgedProof.Proof[10] ^= 0x01
+	if forgedProof.Verify() == nil {
+		t.Error("expected forged proof to be rejected")
+	}
+
+	// rejected blobs are dropped for good rather than retried
+	content, err := checkBlob(forgedHash)
+	if content != nil || err != nil {
+		t.Errorf("expected a forged blob to be skipped without an error, got %v and %v", content, err)
+	}
+}
+
+func TestSourceBlobVerifyBlock(t *testing.T) {
+	sourceBlob := func(message string) *SourceBlob {
+		data, err := EncodeDataToBlob([]byte(message))
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+		commitment, err := kzg4844.BlobToCommitment(data)
+		if err != nil {
+			t.Fatalf("commitment error: %v", err)
+		}
+		return &SourceBlob{
+			VersionedHash: kzg4844.CalcBlobHashV1(sha256.New(), &commitment),
+			Commitment:    &commitment,
+			Data:          data,
+		}
+	}
+	included := sourceBlob("included")
+	tx := types.NewTx(&types.BlobTx{BlobHashes: []common.Hash{included.VersionedHash}})
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody(types.Body{Transactions: []*types.Transaction{tx}})
+
+	if err := included.VerifyBlock(block); err != nil {
+		t.Fatalf("expected the included blob to pass, got %v", err)
+	}
+	// a forged sidecar is consistent with itself but not with the block
+	forged := sourceBlob("forged")
+	if err := forged.Verify(); err != nil {
+		t.Fatalf("expected the forged sidecar to verify on its own, got %v", err)
+	}
+	if forged.VerifyBlock(block) == nil {
+		t.Error("expected a blob missing from the block to be rejected")
+	}
+	otherTx := common.Hash{0x01}
+	included.TxHash = &otherTx
+	if included.VerifyBlock(block) == nil {
+		t.Error("expected a blob claimed by another transaction to be rejected")
+	}
+}
@@ -0,0 +1 @@
+DROP TABLE message.blob_fee;
@@ -0,0 +1,5 @@
+CREATE TABLE message.blob_fee (
+  tx_hash BYTEA PRIMARY KEY,
+  fee BIGINT NOT NULL,
+  submit_time TIMESTAMP NOT NULL
+);
@@ -0,0 +1,13 @@
+DROP TABLE message.blob_tx;
+
+DROP INDEX message.blob_submission_nonce_idx;
+DROP INDEX message.blob_submission_state_idx;
+
+ALTER TABLE message.blob_submission
+  DROP COLUMN updated_at,
+  DROP COLUMN error,
+  DROP COLUMN tx_hash,
+  DROP COLUMN nonce,
+  DROP COLUMN state;
+
+DROP TYPE message.submission_state;
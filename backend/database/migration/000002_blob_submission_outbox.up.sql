@@ -0,0 +1,21 @@
+CREATE TYPE message.submission_state AS ENUM ('queued', 'in_flight', 'confirmed', 'failed');
+
+ALTER TABLE message.blob_submission
+  ADD COLUMN state message.submission_state NOT NULL DEFAULT 'queued',
+  ADD COLUMN nonce BIGINT,
+  ADD COLUMN tx_hash BYTEA,
+  ADD COLUMN error TEXT,
+  ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE INDEX blob_submission_state_idx ON message.blob_submission (state, id);
+CREATE INDEX blob_submission_nonce_idx ON message.blob_submission (nonce);
+
+CREATE TABLE message.blob_tx (
+  nonce BIGINT PRIMARY KEY,
+  tx_hashes BYTEA[] NOT NULL,
+  payloads BYTEA[] NOT NULL,
+  gas_tip_cap BIGINT NOT NULL,
+  gas_fee_cap BIGINT NOT NULL,
+  blob_fee_cap BIGINT NOT NULL,
+  sent_at TIMESTAMP NOT NULL
+);
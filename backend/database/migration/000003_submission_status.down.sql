@@ -0,0 +1,15 @@
+ALTER TABLE message.blob_submission
+  DROP COLUMN block_number,
+  DROP COLUMN versioned_hash;
+
+UPDATE message.blob_submission SET state = 'confirmed' WHERE state = 'finalized';
+
+DROP INDEX message.blob_submission_state_idx;
+ALTER TABLE message.blob_submission ALTER COLUMN state DROP DEFAULT;
+ALTER TYPE message.submission_state RENAME TO submission_state_old;
+CREATE TYPE message.submission_state AS ENUM ('queued', 'in_flight', 'confirmed', 'failed');
+ALTER TABLE message.blob_submission
+  ALTER COLUMN state TYPE message.submission_state USING state::TEXT::message.submission_state;
+ALTER TABLE message.blob_submission ALTER COLUMN state SET DEFAULT 'queued';
+DROP TYPE message.submission_state_old;
+CREATE INDEX blob_submission_state_idx ON message.blob_submission (state, id);
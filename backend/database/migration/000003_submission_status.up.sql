@@ -0,0 +1,5 @@
+ALTER TYPE message.submission_state ADD VALUE 'finalized' AFTER 'confirmed';
+
+ALTER TABLE message.blob_submission
+  ADD COLUMN versioned_hash BYTEA,
+  ADD COLUMN block_number BIGINT;
@@ -0,0 +1,9 @@
+ALTER TABLE message.blob_update DROP COLUMN finalized_block;
+
+DROP TABLE message.chain_block;
+
+DROP INDEX message.blob_block_number_idx;
+
+ALTER TABLE message.blob
+  DROP COLUMN block_number,
+  DROP COLUMN block_hash;
@@ -0,0 +1,13 @@
+ALTER TABLE message.blob
+  ADD COLUMN block_number BIGINT,
+  ADD COLUMN block_hash BYTEA;
+
+CREATE INDEX blob_block_number_idx ON message.blob (block_number);
+
+CREATE TABLE message.chain_block (
+  block_number BIGINT PRIMARY KEY,
+  block_hash BYTEA NOT NULL,
+  parent_hash BYTEA NOT NULL
+);
+
+ALTER TABLE message.blob_update ADD COLUMN finalized_block BIGINT NOT NULL DEFAULT 0;
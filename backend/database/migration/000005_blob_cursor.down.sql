@@ -0,0 +1,3 @@
+DROP TABLE message.ingested_blob;
+
+ALTER TABLE message.blob_update DROP COLUMN blob_index;
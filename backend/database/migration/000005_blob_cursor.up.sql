@@ -0,0 +1,10 @@
+ALTER TABLE message.blob_update ADD COLUMN blob_index INT NOT NULL DEFAULT 0;
+
+CREATE TABLE message.ingested_blob (
+  versioned_hash BYTEA PRIMARY KEY,
+  block_number BIGINT NOT NULL,
+  block_hash BYTEA NOT NULL,
+  blob_index INT NOT NULL
+);
+
+CREATE INDEX ingested_blob_block_number_idx ON message.ingested_blob (block_number);
@@ -0,0 +1 @@
+DROP TABLE message.backfill_range;
@@ -0,0 +1,6 @@
+CREATE TABLE message.backfill_range (
+  from_block BIGINT NOT NULL,
+  to_block BIGINT NOT NULL,
+  completed_at TIMESTAMP,
+  PRIMARY KEY (from_block, to_block)
+);
@@ -0,0 +1 @@
+DROP TABLE message.failed_blob;
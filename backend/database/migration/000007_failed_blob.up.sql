@@ -0,0 +1,12 @@
+CREATE TABLE message.failed_blob (
+  versioned_hash BYTEA PRIMARY KEY,
+  block_number BIGINT NOT NULL,
+  blob_index INT NOT NULL,
+  error TEXT NOT NULL,
+  attempts INT NOT NULL DEFAULT 1,
+  next_attempt_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX failed_blob_next_attempt_at_idx ON message.failed_blob (next_attempt_at);
@@ -0,0 +1,4 @@
+ALTER TABLE message.blob
+  DROP COLUMN tx_hash,
+  DROP COLUMN versioned_hash,
+  DROP COLUMN blob_position;
@@ -0,0 +1,4 @@
+ALTER TABLE message.blob
+  ADD COLUMN tx_hash BYTEA,
+  ADD COLUMN versioned_hash BYTEA,
+  ADD COLUMN blob_position INT;
@@ -0,0 +1,11 @@
+DROP INDEX message.blob_submission_id_idx;
+
+ALTER TABLE message.blob ADD COLUMN needs_submission BOOLEAN NOT NULL DEFAULT false;
+UPDATE message.blob SET needs_submission = true WHERE state IN ('pending', 'submitted');
+ALTER TABLE message.blob ALTER COLUMN needs_submission DROP DEFAULT;
+
+ALTER TABLE message.blob
+  DROP COLUMN state,
+  DROP COLUMN submission_id;
+
+DROP TYPE message.blob_state;
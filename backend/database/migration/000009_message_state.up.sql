@@ -0,0 +1,14 @@
+CREATE TYPE message.blob_state AS ENUM ('pending', 'submitted', 'on_chain', 'finalized');
+
+ALTER TABLE message.blob
+  ADD COLUMN state message.blob_state NOT NULL DEFAULT 'on_chain',
+  ADD COLUMN submission_id INT;
+
+UPDATE message.blob SET state = 'pending' WHERE needs_submission AND block_number IS NULL;
+UPDATE message.blob SET state = 'finalized'
+WHERE block_number <= (SELECT finalized_block FROM message.blob_update LIMIT 1);
+
+ALTER TABLE message.blob ALTER COLUMN state DROP DEFAULT;
+ALTER TABLE message.blob DROP COLUMN needs_submission;
+
+CREATE INDEX blob_submission_id_idx ON message.blob (submission_id);
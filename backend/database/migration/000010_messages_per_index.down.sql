@@ -0,0 +1,15 @@
+-- only the latest message per index survives
+DELETE FROM message.blob_submission a USING message.blob_submission b
+WHERE a.index = b.index AND a.pubkey = b.pubkey AND a.id < b.id;
+
+ALTER TABLE message.blob_submission ADD CONSTRAINT blob_submission_index_pubkey_key UNIQUE (index, pubkey);
+
+ALTER TABLE message.blob DROP CONSTRAINT blob_versioned_hash_position_key;
+
+DROP INDEX message.blob_index_position_idx;
+
+ALTER TABLE message.blob DROP COLUMN blob_index;
+
+DELETE FROM message.blob a USING message.blob b WHERE a.index = b.index AND a.id < b.id;
+
+ALTER TABLE message.blob ADD CONSTRAINT blob_index_key UNIQUE (index);
@@ -0,0 +1,12 @@
+ALTER TABLE message.blob DROP CONSTRAINT blob_index_key;
+
+ALTER TABLE message.blob ADD COLUMN blob_index INT;
+
+UPDATE message.blob SET blob_index = ingested_blob.blob_index
+FROM message.ingested_blob WHERE ingested_blob.versioned_hash = blob.versioned_hash;
+
+CREATE INDEX blob_index_position_idx ON message.blob (index, block_number, blob_index, blob_position);
+
+ALTER TABLE message.blob ADD CONSTRAINT blob_versioned_hash_position_key UNIQUE (versioned_hash, blob_position);
+
+ALTER TABLE message.blob_submission DROP CONSTRAINT blob_submission_index_pubkey_key;
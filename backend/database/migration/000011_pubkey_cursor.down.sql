@@ -0,0 +1,4 @@
+DROP INDEX message.pubkey_block_number_idx;
+DROP INDEX message.pubkey_submit_time_idx;
+
+ALTER TABLE message.pubkey DROP COLUMN block_number;
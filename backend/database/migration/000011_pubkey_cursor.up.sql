@@ -0,0 +1,4 @@
+ALTER TABLE message.pubkey ADD COLUMN block_number BIGINT;
+
+CREATE INDEX pubkey_submit_time_idx ON message.pubkey (submit_time, pubkey);
+CREATE INDEX pubkey_block_number_idx ON message.pubkey (block_number);
@@ -0,0 +1,3 @@
+ALTER TABLE message.blob_tx DROP COLUMN carrier;
+
+DROP TYPE message.tx_carrier;
@@ -0,0 +1,4 @@
+CREATE TYPE message.tx_carrier AS ENUM ('blob', 'calldata');
+
+ALTER TABLE message.blob_tx ADD COLUMN carrier message.tx_carrier NOT NULL DEFAULT 'blob';
+ALTER TABLE message.blob_tx ALTER COLUMN carrier DROP DEFAULT;
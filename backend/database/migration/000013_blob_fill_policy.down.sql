@@ -0,0 +1,5 @@
+DROP TABLE message.blob_batch;
+
+DROP TYPE message.flush_trigger;
+
+ALTER TABLE message.blob_submission DROP COLUMN queued_at;
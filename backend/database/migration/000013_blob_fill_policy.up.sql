@@ -0,0 +1,16 @@
+ALTER TABLE message.blob_submission ADD COLUMN queued_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE TYPE message.flush_trigger AS ENUM ('fill', 'deadline', 'fee');
+
+CREATE TABLE message.blob_batch (
+  id SERIAL PRIMARY KEY,
+  versioned_hash BYTEA NOT NULL,
+  nonce BIGINT,
+  message_count INT NOT NULL,
+  fill_ratio DOUBLE PRECISION NOT NULL,
+  wait_ms BIGINT NOT NULL,
+  trigger message.flush_trigger NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX blob_batch_created_at_idx ON message.blob_batch (created_at);
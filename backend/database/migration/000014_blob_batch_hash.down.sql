@@ -0,0 +1 @@
+DROP INDEX message.blob_batch_versioned_hash_idx;
@@ -0,0 +1 @@
+CREATE INDEX blob_batch_versioned_hash_idx ON message.blob_batch (versioned_hash);
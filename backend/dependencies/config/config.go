@@ -4,6 +4,7 @@ package config
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/joho/godotenv"
@@ -14,6 +15,9 @@ import (
 type Environment string
 type LogLevel string
 type LogType string
+type BlobSource string
+type ArchiveStore string
+type DABackend string
 
 const (
 	EnvironmentDevelopment Environment = "development"
@@ -35,16 +39,110 @@ const (
 	LogTypePlain      LogType = "plain"
 )
 
+const (
+	BlobSourceBeacon   BlobSource = "beacon"
+	BlobSourceBlobscan BlobSource = "blobscan"
+	BlobSourceLocal    BlobSource = "local"
+	// only blob transactions of the relays or to the inbox, read through
+	// rpc_url and beacon_url
+	BlobSourceExecution BlobSource = "execution"
+	// blobs announced by the inbox contract, found through eth_getLogs
+	BlobSourceInbox BlobSource = "inbox"
+	// re-index from the configured archive store
+	BlobSourceArchive BlobSource = "archive"
+)
+
+const (
+	// EIP-4844 blobs on the chain of rpc_url
+	DABackendL1Blob DABackend = "l1_blob"
+	// plain calldata on the chain of rpc_url, e.g. an L2 such as World Chain
+	DABackendCalldata DABackend = "calldata"
+	// files in da_dir without any chain, for development and CI
+	DABackendLocal DABackend = "local"
+)
+
+const (
+	ArchiveStoreLocal ArchiveStore = "local"
+	ArchiveStoreS3    ArchiveStore = "s3"
+)
+
 type Config struct {
 	Environment Environment `koanf:"environment"  validate:"required,oneof=development staging production"`
 	LogLevel    LogLevel    `koanf:"log_level"    validate:"required,oneof=trace debug info warn error fatal panic"`
 	LogType     LogType     `koanf:"log_type"     validate:"required,oneof=structured plain"`
 	Port        int         `koanf:"port"     validate:"required"`
 	PrivateKey  string      `koanf:"private_key" validate:"required"`
-	RpcUrl      string      `koanf:"rpc_url" validate:"required"`
-	ChainId     uint64      `koanf:"chain_id" validate:"required"`
+	RpcUrl      string      `koanf:"rpc_url"`
+	ChainId     uint64      `koanf:"chain_id"`
 	BlobUpdate  bool        `koanf:"blob_update"`
 	Database    string      `koanf:"database"                validate:"required,url"`
+	// bearer token for the /admin routes, they are disabled if it is empty
+	AdminToken string `koanf:"admin_token"`
+
+	// where the relay publishes its payloads, the indexer fetches them back
+	// from the same backend
+	DABackend DABackend `koanf:"da_backend" validate:"required,oneof=l1_blob calldata local"`
+	DADir     string    `koanf:"da_dir"`
+	// where the indexer reads blobs from with the l1_blob backend, the url
+	// or directory of the selected source has to be set
+	BlobSource  BlobSource `koanf:"blob_source"  validate:"required,oneof=beacon blobscan local archive execution inbox"`
+	BeaconUrl   string     `koanf:"beacon_url"   validate:"omitempty,url"`
+	BlobscanUrl string     `koanf:"blobscan_url" validate:"omitempty,url"`
+	BlobDir     string     `koanf:"blob_dir"`
+	// comma separated senders whose transactions the execution source and
+	// the calldata backend pick up, the address of private_key is always
+	// included
+	RelayAddresses []string `koanf:"relay_addresses" validate:"dive,eth_addr"`
+	// address of the BlobInbox contract, with use_inbox set blob
+	// transactions call it to announce their blobs
+	InboxAddress string `koanf:"inbox_address" validate:"omitempty,eth_addr"`
+	UseInbox     bool   `koanf:"use_inbox"`
+	// block the indexer starts at on an empty database
+	StartBlock uint64 `koanf:"start_block" validate:"required"`
+	// block range [backfill_from, backfill_to) indexed in parallel next to
+	// the live indexer, the backfill is disabled if backfill_to is not set
+	BackfillFrom      uint64 `koanf:"backfill_from"`
+	BackfillTo        uint64 `koanf:"backfill_to"         validate:"omitempty,gtfield=BackfillFrom"`
+	BackfillChunkSize uint64 `koanf:"backfill_chunk_size" validate:"required"`
+	BackfillWorkers   int    `koanf:"backfill_workers"    validate:"required,gt=0"`
+	// where ingested and submitted blobs are archived, archiving is disabled
+	// if no store is set
+	ArchiveStore       ArchiveStore `koanf:"archive_store"         validate:"omitempty,oneof=local s3"`
+	ArchiveDir         string       `koanf:"archive_dir"`
+	ArchiveS3Endpoint  string       `koanf:"archive_s3_endpoint"`
+	ArchiveS3Bucket    string       `koanf:"archive_s3_bucket"`
+	ArchiveS3AccessKey string       `koanf:"archive_s3_access_key"`
+	ArchiveS3SecretKey string       `koanf:"archive_s3_secret_key"`
+	ArchiveS3Secure    bool         `koanf:"archive_s3_secure"`
+	// only serve messages from finalized blocks, others may still be reorged
+	FinalizedOnly bool `koanf:"finalized_only"`
+
+	// fee multipliers applied to the latest base fees and the median priority fee
+	BaseFeeMultiplier float64 `koanf:"base_fee_multiplier" validate:"gte=1"`
+	BlobFeeMultiplier float64 `koanf:"blob_fee_multiplier" validate:"gte=1"`
+	TipMultiplier     float64 `koanf:"tip_multiplier"      validate:"gt=0"`
+	// blob base fee in wei above which the l1_blob backend posts payloads as
+	// calldata whenever that is cheaper, the execution blob source indexes
+	// the calldata of the relays, the fallback is disabled if it is not set
+	CalldataFallbackFee uint64 `koanf:"calldata_fallback_fee"`
+	// hard spending caps in wei, a transaction exceeding either is not sent,
+	// calldata on OP-stack chains counts its L1 data fee as well
+	MaxTxFee    uint64 `koanf:"max_tx_fee"    validate:"required"`
+	MaxDailyFee uint64 `koanf:"max_daily_fee" validate:"required,gtefield=MaxTxFee"`
+	// the submitter waits for queued messages to fill the last blob up to
+	// fill_target unless the oldest of them waited for max_latency or the
+	// blob base fee in wei is below flush_blob_fee, which is disabled if it
+	// is not set
+	FillTarget   float64       `koanf:"fill_target"    validate:"gt=0,lte=1"`
+	MaxLatency   time.Duration `koanf:"max_latency"    validate:"required"`
+	FlushBlobFee uint64        `koanf:"flush_blob_fee"`
+	// blocks on top of a blob transaction before its messages count as sent
+	ConfirmationDepth uint64 `koanf:"confirmation_depth"`
+	// how long a blob transaction may stay pending before it is replaced
+	ReplaceAfter time.Duration `koanf:"replace_after" validate:"required"`
+	// how long confirmed submissions keep their payload, their status is
+	// kept after that
+	SubmissionRetention time.Duration `koanf:"submission_retention" validate:"required"`
 }
 
 func NewConfig(envFiles ...string) (*Config, error) {
@@ -58,6 +156,10 @@ func NewConfig(envFiles ...string) (*Config, error) {
 			func(key string, value string) (string, interface{}) {
 				key = strings.TrimPrefix(key, "PDM_")
 				key = strings.ToLower(key)
+				// koanf does not split lists from the environment itself
+				if key == "relay_addresses" {
+					return key, splitList(value)
+				}
 				return key, value
 			},
 		),
@@ -72,6 +174,54 @@ func NewConfig(envFiles ...string) (*Config, error) {
 	if c.LogLevel == "" {
 		c.LogLevel = LogLevelInfo
 	}
+	if c.DABackend == "" {
+		c.DABackend = DABackendL1Blob
+	}
+	if c.BlobSource == "" {
+		c.BlobSource = BlobSourceBlobscan
+	}
+	if c.BlobSource == BlobSourceBlobscan && c.BlobscanUrl == "" {
+		c.BlobscanUrl = "https://api.sepolia.blobscan.com"
+	}
+	if c.StartBlock == 0 {
+		c.StartBlock = 8698539
+	}
+	if c.BackfillChunkSize == 0 {
+		c.BackfillChunkSize = 1000
+	}
+	if c.BackfillWorkers == 0 {
+		c.BackfillWorkers = 4
+	}
+	if c.BaseFeeMultiplier == 0 {
+		c.BaseFeeMultiplier = 2
+	}
+	if c.BlobFeeMultiplier == 0 {
+		c.BlobFeeMultiplier = 2
+	}
+	if c.TipMultiplier == 0 {
+		c.TipMultiplier = 1
+	}
+	if c.MaxTxFee == 0 {
+		c.MaxTxFee = 10_000_000_000_000_000 // 0.01 ETH
+	}
+	if c.MaxDailyFee == 0 {
+		c.MaxDailyFee = 100_000_000_000_000_000 // 0.1 ETH
+	}
+	if c.FillTarget == 0 {
+		c.FillTarget = 0.9
+	}
+	if c.MaxLatency == 0 {
+		c.MaxLatency = time.Minute
+	}
+	if c.ConfirmationDepth == 0 {
+		c.ConfirmationDepth = 3
+	}
+	if c.ReplaceAfter == 0 {
+		c.ReplaceAfter = 2 * time.Minute
+	}
+	if c.SubmissionRetention == 0 {
+		c.SubmissionRetention = 24 * time.Hour
+	}
 
 	validate := validator.New()
 	if err := validate.Struct(c); err != nil {
@@ -79,6 +229,61 @@ func NewConfig(envFiles ...string) (*Config, error) {
 			"Configuration validation failed: " + err.Error(),
 		)
 	}
+	if c.DABackend != DABackendLocal && (c.RpcUrl == "" || c.ChainId == 0) {
+		return nil, errors.New("Configuration validation failed: rpc_url and chain_id are required unless the local da backend is used")
+	}
+	if c.DABackend == DABackendLocal && c.BackfillTo > 0 {
+		return nil, errors.New("Configuration validation failed: backfill_to is not supported by the local da backend")
+	}
+	if c.DABackend == DABackendLocal && c.DADir == "" {
+		return nil, errors.New("Configuration validation failed: da_dir is required for the local da backend")
+	}
+	if c.DABackend != DABackendL1Blob && c.UseInbox {
+		return nil, errors.New("Configuration validation failed: use_inbox requires the l1_blob da backend")
+	}
+	if c.DABackend != DABackendL1Blob && c.CalldataFallbackFee > 0 {
+		return nil, errors.New("Configuration validation failed: calldata_fallback_fee requires the l1_blob da backend")
+	}
+	// the archive keeps the calldata payloads the execution source indexed
+	if c.CalldataFallbackFee > 0 && c.BlobSource != BlobSourceExecution && c.BlobSource != BlobSourceArchive {
+		return nil, errors.New("Configuration validation failed: calldata_fallback_fee requires the execution or archive blob source")
+	}
+	if c.BlobSource == BlobSourceBeacon && c.BeaconUrl == "" {
+		return nil, errors.New("Configuration validation failed: beacon_url is required for the beacon blob source")
+	}
+	if c.BlobSource == BlobSourceExecution && c.BeaconUrl == "" {
+		return nil, errors.New("Configuration validation failed: beacon_url is required for the execution blob source")
+	}
+	if c.BlobSource == BlobSourceInbox && (c.BeaconUrl == "" || c.InboxAddress == "") {
+		return nil, errors.New("Configuration validation failed: beacon_url and inbox_address are required for the inbox blob source")
+	}
+	if c.UseInbox && c.InboxAddress == "" {
+		return nil, errors.New("Configuration validation failed: inbox_address is required to use the inbox")
+	}
+	if c.BlobSource == BlobSourceLocal && c.BlobDir == "" {
+		return nil, errors.New("Configuration validation failed: blob_dir is required for the local blob source")
+	}
+	if c.BlobSource == BlobSourceArchive && c.ArchiveStore == "" {
+		return nil, errors.New("Configuration validation failed: archive_store is required for the archive blob source")
+	}
+	if c.ArchiveStore == ArchiveStoreLocal && c.ArchiveDir == "" {
+		return nil, errors.New("Configuration validation failed: archive_dir is required for the local archive store")
+	}
+	if c.ArchiveStore == ArchiveStoreS3 && (c.ArchiveS3Endpoint == "" || c.ArchiveS3Bucket == "") {
+		return nil, errors.New("Configuration validation failed: archive_s3_endpoint and archive_s3_bucket are required for the s3 archive store")
+	}
 
 	return &c, nil
 }
+
+// splitList splits a comma separated value and drops empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
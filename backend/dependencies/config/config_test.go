@@ -0,0 +1,55 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewConfigRelayAddresses(t *testing.T) {
+	t.Setenv("PDM_ENVIRONMENT", "development")
+	t.Setenv("PDM_LOG_TYPE", "plain")
+	t.Setenv("PDM_PORT", "8080")
+	t.Setenv("PDM_PRIVATE_KEY", "0x01")
+	t.Setenv("PDM_RPC_URL", "http://localhost:8545")
+	t.Setenv("PDM_CHAIN_ID", "11155111")
+	t.Setenv("PDM_DATABASE", "postgres://localhost/pdm")
+	t.Setenv("PDM_RELAY_ADDRESSES", "0x0000000000000000000000000000000000000001, 0x0000000000000000000000000000000000000002")
+
+	c, err := NewConfig()
+	if err != nil {
+		t.Fatalf("config error: %v", err)
+	}
+	expected := []string{
+		"0x0000000000000000000000000000000000000001",
+		"0x0000000000000000000000000000000000000002",
+	}
+	if !slices.Equal(c.RelayAddresses, expected) {
+		t.Errorf("expected relay addresses %v, got %v", expected, c.RelayAddresses)
+	}
+
+	t.Setenv("PDM_RELAY_ADDRESSES", "0x0000000000000000000000000000000000000001,0x02")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected an invalid relay address to be rejected")
+	}
+}
+
+func TestNewConfigLocalBackend(t *testing.T) {
+	t.Setenv("PDM_ENVIRONMENT", "development")
+	t.Setenv("PDM_LOG_TYPE", "plain")
+	t.Setenv("PDM_PORT", "8080")
+	t.Setenv("PDM_PRIVATE_KEY", "0x01")
+	t.Setenv("PDM_DATABASE", "postgres://localhost/pdm")
+	t.Setenv("PDM_DA_BACKEND", "local")
+	t.Setenv("PDM_DA_DIR", t.TempDir())
+	t.Setenv("PDM_BLOB_SOURCE", "local")
+	t.Setenv("PDM_BLOB_DIR", t.TempDir())
+
+	// the local backend runs without a chain
+	if _, err := NewConfig(); err != nil {
+		t.Fatalf("config error: %v", err)
+	}
+	t.Setenv("PDM_DA_BACKEND", "l1_blob")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected rpc_url and chain_id to be required with a chain")
+	}
+}
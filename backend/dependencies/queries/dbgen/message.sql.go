@@ -10,8 +10,75 @@ import (
 	"time"
 )
 
+const addBackfillRange = `-- name: AddBackfillRange :exec
+INSERT INTO message.backfill_range (from_block, to_block) VALUES ($1, $2)
+ON CONFLICT (from_block, to_block) DO NOTHING
+`
+
+type AddBackfillRangeParams struct {
+	FromBlock int64
+	ToBlock   int64
+}
+
+// AddBackfillRange
+//
+//	INSERT INTO message.backfill_range (from_block, to_block) VALUES ($1, $2)
+//	ON CONFLICT (from_block, to_block) DO NOTHING
+func (q *Queries) AddBackfillRange(ctx context.Context, arg AddBackfillRangeParams) error {
+	_, err := q.db.Exec(ctx, addBackfillRange, arg.FromBlock, arg.ToBlock)
+	return err
+}
+
+const addBlobBatch = `-- name: AddBlobBatch :exec
+INSERT INTO message.blob_batch (versioned_hash, nonce, message_count, fill_ratio, wait_ms, trigger)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type AddBlobBatchParams struct {
+	VersionedHash []byte
+	Nonce         *int64
+	MessageCount  int32
+	FillRatio     float64
+	WaitMs        int64
+	Trigger       MessageFlushTrigger
+}
+
+// AddBlobBatch
+//
+//	INSERT INTO message.blob_batch (versioned_hash, nonce, message_count, fill_ratio, wait_ms, trigger)
+//	VALUES ($1, $2, $3, $4, $5, $6)
+func (q *Queries) AddBlobBatch(ctx context.Context, arg AddBlobBatchParams) error {
+	_, err := q.db.Exec(ctx, addBlobBatch,
+		arg.VersionedHash,
+		arg.Nonce,
+		arg.MessageCount,
+		arg.FillRatio,
+		arg.WaitMs,
+		arg.Trigger,
+	)
+	return err
+}
+
+const addBlobFee = `-- name: AddBlobFee :exec
+INSERT INTO message.blob_fee (tx_hash, fee, submit_time) VALUES ($1, $2, $3)
+`
+
+type AddBlobFeeParams struct {
+	TxHash     []byte
+	Fee        int64
+	SubmitTime time.Time
+}
+
+// AddBlobFee
+//
+//	INSERT INTO message.blob_fee (tx_hash, fee, submit_time) VALUES ($1, $2, $3)
+func (q *Queries) AddBlobFee(ctx context.Context, arg AddBlobFeeParams) error {
+	_, err := q.db.Exec(ctx, addBlobFee, arg.TxHash, arg.Fee, arg.SubmitTime)
+	return err
+}
+
 const addBlobSubmission = `-- name: AddBlobSubmission :one
-INSERT INTO message.blob_submission (index, message, pubkey) VALUES ($1, $2, $3) RETURNING id, index, message, pubkey
+INSERT INTO message.blob_submission (index, message, pubkey) VALUES ($1, $2, $3) RETURNING id, index, message, pubkey, state, nonce, tx_hash, error, updated_at, versioned_hash, block_number, queued_at
 `
 
 type AddBlobSubmissionParams struct {
@@ -22,7 +89,7 @@ type AddBlobSubmissionParams struct {
 
 // AddBlobSubmission
 //
-//	INSERT INTO message.blob_submission (index, message, pubkey) VALUES ($1, $2, $3) RETURNING id, index, message, pubkey
+//	INSERT INTO message.blob_submission (index, message, pubkey) VALUES ($1, $2, $3) RETURNING id, index, message, pubkey, state, nonce, tx_hash, error, updated_at, versioned_hash, block_number, queued_at
 func (q *Queries) AddBlobSubmission(ctx context.Context, arg AddBlobSubmissionParams) (MessageBlobSubmission, error) {
 	row := q.db.QueryRow(ctx, addBlobSubmission, arg.Index, arg.Message, arg.Pubkey)
 	var i MessageBlobSubmission
@@ -31,10 +98,92 @@ func (q *Queries) AddBlobSubmission(ctx context.Context, arg AddBlobSubmissionPa
 		&i.Index,
 		&i.Message,
 		&i.Pubkey,
+		&i.State,
+		&i.Nonce,
+		&i.TxHash,
+		&i.Error,
+		&i.UpdatedAt,
+		&i.VersionedHash,
+		&i.BlockNumber,
+		&i.QueuedAt,
 	)
 	return i, err
 }
 
+const addBlobTx = `-- name: AddBlobTx :exec
+INSERT INTO message.blob_tx (nonce, tx_hashes, payloads, gas_tip_cap, gas_fee_cap, blob_fee_cap, sent_at, carrier)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type AddBlobTxParams struct {
+	Nonce      int64
+	TxHashes   [][]byte
+	Payloads   [][]byte
+	GasTipCap  int64
+	GasFeeCap  int64
+	BlobFeeCap int64
+	SentAt     time.Time
+	Carrier    MessageTxCarrier
+}
+
+// AddBlobTx
+//
+//	INSERT INTO message.blob_tx (nonce, tx_hashes, payloads, gas_tip_cap, gas_fee_cap, blob_fee_cap, sent_at, carrier)
+//	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+func (q *Queries) AddBlobTx(ctx context.Context, arg AddBlobTxParams) error {
+	_, err := q.db.Exec(ctx, addBlobTx,
+		arg.Nonce,
+		arg.TxHashes,
+		arg.Payloads,
+		arg.GasTipCap,
+		arg.GasFeeCap,
+		arg.BlobFeeCap,
+		arg.SentAt,
+		arg.Carrier,
+	)
+	return err
+}
+
+const addChainBlock = `-- name: AddChainBlock :exec
+INSERT INTO message.chain_block (block_number, block_hash, parent_hash) VALUES ($1, $2, $3)
+ON CONFLICT (block_number) DO UPDATE SET block_hash = EXCLUDED.block_hash, parent_hash = EXCLUDED.parent_hash
+`
+
+type AddChainBlockParams struct {
+	BlockNumber int64
+	BlockHash   []byte
+	ParentHash  []byte
+}
+
+// AddChainBlock
+//
+//	INSERT INTO message.chain_block (block_number, block_hash, parent_hash) VALUES ($1, $2, $3)
+//	ON CONFLICT (block_number) DO UPDATE SET block_hash = EXCLUDED.block_hash, parent_hash = EXCLUDED.parent_hash
+func (q *Queries) AddChainBlock(ctx context.Context, arg AddChainBlockParams) error {
+	_, err := q.db.Exec(ctx, addChainBlock, arg.BlockNumber, arg.BlockHash, arg.ParentHash)
+	return err
+}
+
+const addChainPubkey = `-- name: AddChainPubkey :exec
+INSERT INTO message.pubkey (pubkey, submit_time, block_number) VALUES ($1, $2, $3)
+ON CONFLICT (pubkey) DO UPDATE SET block_number = COALESCE(pubkey.block_number, EXCLUDED.block_number)
+`
+
+type AddChainPubkeyParams struct {
+	Pubkey      []byte
+	SubmitTime  time.Time
+	BlockNumber *int64
+}
+
+// AddChainPubkey
+//
+//	INSERT INTO message.pubkey (pubkey, submit_time, block_number) VALUES ($1, $2, $3)
+//	ON CONFLICT (pubkey) DO UPDATE SET block_number = COALESCE(pubkey.block_number, EXCLUDED.block_number)
+func (q *Queries) AddChainPubkey(ctx context.Context, arg AddChainPubkeyParams) error {
+	_, err := q.db.Exec(ctx, addChainPubkey, arg.Pubkey, arg.SubmitTime, arg.BlockNumber)
+	return err
+}
+
 const addENSSubdomain = `-- name: AddENSSubdomain :exec
 INSERT INTO message.ens_subdomain (subdomain, address) VALUES ($1, $2)
 `
@@ -52,30 +201,107 @@ func (q *Queries) AddENSSubdomain(ctx context.Context, arg AddENSSubdomainParams
 	return err
 }
 
+const addFailedBlob = `-- name: AddFailedBlob :exec
+INSERT INTO message.failed_blob (versioned_hash, block_number, blob_index, error, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (versioned_hash) DO NOTHING
+`
+
+type AddFailedBlobParams struct {
+	VersionedHash []byte
+	BlockNumber   int64
+	BlobIndex     int32
+	Error         string
+	NextAttemptAt time.Time
+}
+
+// AddFailedBlob
+//
+//	INSERT INTO message.failed_blob (versioned_hash, block_number, blob_index, error, next_attempt_at)
+//	VALUES ($1, $2, $3, $4, $5)
+//	ON CONFLICT (versioned_hash) DO NOTHING
+func (q *Queries) AddFailedBlob(ctx context.Context, arg AddFailedBlobParams) error {
+	_, err := q.db.Exec(ctx, addFailedBlob,
+		arg.VersionedHash,
+		arg.BlockNumber,
+		arg.BlobIndex,
+		arg.Error,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const addIngestedBlob = `-- name: AddIngestedBlob :execrows
+INSERT INTO message.ingested_blob (versioned_hash, block_number, block_hash, blob_index) VALUES ($1, $2, $3, $4)
+ON CONFLICT (versioned_hash) DO NOTHING
+`
+
+type AddIngestedBlobParams struct {
+	VersionedHash []byte
+	BlockNumber   int64
+	BlockHash     []byte
+	BlobIndex     int32
+}
+
+// AddIngestedBlob
+//
+//	INSERT INTO message.ingested_blob (versioned_hash, block_number, block_hash, blob_index) VALUES ($1, $2, $3, $4)
+//	ON CONFLICT (versioned_hash) DO NOTHING
+func (q *Queries) AddIngestedBlob(ctx context.Context, arg AddIngestedBlobParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addIngestedBlob,
+		arg.VersionedHash,
+		arg.BlockNumber,
+		arg.BlockHash,
+		arg.BlobIndex,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const addMessage = `-- name: AddMessage :one
-INSERT INTO message.blob (index, message, submit_time, needs_submission) VALUES ($1, $2, $3, $4) 
-ON CONFLICT (index) DO UPDATE SET submit_time = EXCLUDED.submit_time, needs_submission = EXCLUDED.needs_submission 
-RETURNING id, index, message, submit_time, needs_submission
+INSERT INTO message.blob (
+  index, message, submit_time, state, submission_id,
+  block_number, block_hash, tx_hash, versioned_hash, blob_index, blob_position
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, index, message, submit_time, block_number, block_hash, tx_hash, versioned_hash, blob_position, state, submission_id, blob_index
 `
 
 type AddMessageParams struct {
-	Index           []byte
-	Message         []byte
-	SubmitTime      time.Time
-	NeedsSubmission bool
+	Index         []byte
+	Message       []byte
+	SubmitTime    time.Time
+	State         MessageBlobState
+	SubmissionID  *int32
+	BlockNumber   *int64
+	BlockHash     []byte
+	TxHash        []byte
+	VersionedHash []byte
+	BlobIndex     *int32
+	BlobPosition  *int32
 }
 
 // AddMessage
 //
-//	INSERT INTO message.blob (index, message, submit_time, needs_submission) VALUES ($1, $2, $3, $4)
-//	ON CONFLICT (index) DO UPDATE SET submit_time = EXCLUDED.submit_time, needs_submission = EXCLUDED.needs_submission
-//	RETURNING id, index, message, submit_time, needs_submission
+//	INSERT INTO message.blob (
+//	  index, message, submit_time, state, submission_id,
+//	  block_number, block_hash, tx_hash, versioned_hash, blob_index, blob_position
+//	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+//	RETURNING id, index, message, submit_time, block_number, block_hash, tx_hash, versioned_hash, blob_position, state, submission_id, blob_index
 func (q *Queries) AddMessage(ctx context.Context, arg AddMessageParams) (MessageBlob, error) {
 	row := q.db.QueryRow(ctx, addMessage,
 		arg.Index,
 		arg.Message,
 		arg.SubmitTime,
-		arg.NeedsSubmission,
+		arg.State,
+		arg.SubmissionID,
+		arg.BlockNumber,
+		arg.BlockHash,
+		arg.TxHash,
+		arg.VersionedHash,
+		arg.BlobIndex,
+		arg.BlobPosition,
 	)
 	var i MessageBlob
 	err := row.Scan(
@@ -83,7 +309,14 @@ func (q *Queries) AddMessage(ctx context.Context, arg AddMessageParams) (Message
 		&i.Index,
 		&i.Message,
 		&i.SubmitTime,
-		&i.NeedsSubmission,
+		&i.BlockNumber,
+		&i.BlockHash,
+		&i.TxHash,
+		&i.VersionedHash,
+		&i.BlobPosition,
+		&i.State,
+		&i.SubmissionID,
+		&i.BlobIndex,
 	)
 	return i, err
 }
@@ -91,7 +324,7 @@ func (q *Queries) AddMessage(ctx context.Context, arg AddMessageParams) (Message
 const addPubkey = `-- name: AddPubkey :one
 INSERT INTO message.pubkey (pubkey, submit_time) VALUES ($1, $2) 
 ON CONFLICT (pubkey) DO UPDATE SET submit_time = EXCLUDED.submit_time 
-RETURNING pubkey, submit_time
+RETURNING pubkey, submit_time, block_number
 `
 
 type AddPubkeyParams struct {
@@ -103,23 +336,23 @@ type AddPubkeyParams struct {
 //
 //	INSERT INTO message.pubkey (pubkey, submit_time) VALUES ($1, $2)
 //	ON CONFLICT (pubkey) DO UPDATE SET submit_time = EXCLUDED.submit_time
-//	RETURNING pubkey, submit_time
+//	RETURNING pubkey, submit_time, block_number
 func (q *Queries) AddPubkey(ctx context.Context, arg AddPubkeyParams) (MessagePubkey, error) {
 	row := q.db.QueryRow(ctx, addPubkey, arg.Pubkey, arg.SubmitTime)
 	var i MessagePubkey
-	err := row.Scan(&i.Pubkey, &i.SubmitTime)
+	err := row.Scan(&i.Pubkey, &i.SubmitTime, &i.BlockNumber)
 	return i, err
 }
 
-const getBlobSubmissions = `-- name: GetBlobSubmissions :many
-SELECT id, index, message, pubkey FROM message.blob_submission
+const claimBlobSubmissions = `-- name: ClaimBlobSubmissions :many
+SELECT id, index, message, pubkey, state, nonce, tx_hash, error, updated_at, versioned_hash, block_number, queued_at FROM message.blob_submission WHERE state = 'queued' ORDER BY id FOR UPDATE SKIP LOCKED
 `
 
-// GetBlobSubmissions
+// ClaimBlobSubmissions
 //
-//	SELECT id, index, message, pubkey FROM message.blob_submission
-func (q *Queries) GetBlobSubmissions(ctx context.Context) ([]MessageBlobSubmission, error) {
-	rows, err := q.db.Query(ctx, getBlobSubmissions)
+//	SELECT id, index, message, pubkey, state, nonce, tx_hash, error, updated_at, versioned_hash, block_number, queued_at FROM message.blob_submission WHERE state = 'queued' ORDER BY id FOR UPDATE SKIP LOCKED
+func (q *Queries) ClaimBlobSubmissions(ctx context.Context) ([]MessageBlobSubmission, error) {
+	rows, err := q.db.Query(ctx, claimBlobSubmissions)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +365,286 @@ func (q *Queries) GetBlobSubmissions(ctx context.Context) ([]MessageBlobSubmissi
 			&i.Index,
 			&i.Message,
 			&i.Pubkey,
+			&i.State,
+			&i.Nonce,
+			&i.TxHash,
+			&i.Error,
+			&i.UpdatedAt,
+			&i.VersionedHash,
+			&i.BlockNumber,
+			&i.QueuedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const compactConfirmedBlobSubmissions = `-- name: CompactConfirmedBlobSubmissions :exec
+UPDATE message.blob_submission SET index = '', message = '', pubkey = ''
+WHERE state IN ('confirmed', 'finalized') AND updated_at < $1 AND octet_length(message) > 0
+`
+
+// CompactConfirmedBlobSubmissions
+//
+//	UPDATE message.blob_submission SET index = '', message = '', pubkey = ''
+//	WHERE state IN ('confirmed', 'finalized') AND updated_at < $1 AND octet_length(message) > 0
+func (q *Queries) CompactConfirmedBlobSubmissions(ctx context.Context, updatedAt time.Time) error {
+	_, err := q.db.Exec(ctx, compactConfirmedBlobSubmissions, updatedAt)
+	return err
+}
+
+const completeBackfillRange = `-- name: CompleteBackfillRange :exec
+UPDATE message.backfill_range SET completed_at = NOW() WHERE from_block = $1 AND to_block = $2
+`
+
+type CompleteBackfillRangeParams struct {
+	FromBlock int64
+	ToBlock   int64
+}
+
+// CompleteBackfillRange
+//
+//	UPDATE message.backfill_range SET completed_at = NOW() WHERE from_block = $1 AND to_block = $2
+func (q *Queries) CompleteBackfillRange(ctx context.Context, arg CompleteBackfillRangeParams) error {
+	_, err := q.db.Exec(ctx, completeBackfillRange, arg.FromBlock, arg.ToBlock)
+	return err
+}
+
+const confirmBlobSubmissions = `-- name: ConfirmBlobSubmissions :exec
+UPDATE message.blob_submission SET state = 'confirmed', tx_hash = $2, block_number = $3, updated_at = NOW()
+WHERE nonce = $1 AND state = 'in_flight'
+`
+
+type ConfirmBlobSubmissionsParams struct {
+	Nonce       *int64
+	TxHash      []byte
+	BlockNumber *int64
+}
+
+// ConfirmBlobSubmissions
+//
+//	UPDATE message.blob_submission SET state = 'confirmed', tx_hash = $2, block_number = $3, updated_at = NOW()
+//	WHERE nonce = $1 AND state = 'in_flight'
+func (q *Queries) ConfirmBlobSubmissions(ctx context.Context, arg ConfirmBlobSubmissionsParams) error {
+	_, err := q.db.Exec(ctx, confirmBlobSubmissions, arg.Nonce, arg.TxHash, arg.BlockNumber)
+	return err
+}
+
+const confirmMessage = `-- name: ConfirmMessage :execrows
+UPDATE message.blob SET submit_time = $1, state = 'on_chain',
+  block_number = $2, block_hash = $3, tx_hash = $4,
+  versioned_hash = $5, blob_index = $6, blob_position = $7
+WHERE id = (
+  SELECT pending.id FROM message.blob pending
+  WHERE pending.index = $8 AND pending.message = $9 AND pending.block_number IS NULL
+  ORDER BY pending.id LIMIT 1
+)
+`
+
+type ConfirmMessageParams struct {
+	SubmitTime    time.Time
+	BlockNumber   *int64
+	BlockHash     []byte
+	TxHash        []byte
+	VersionedHash []byte
+	BlobIndex     *int32
+	BlobPosition  *int32
+	Index         []byte
+	Message       []byte
+}
+
+// ConfirmMessage
+//
+//	UPDATE message.blob SET submit_time = $1, state = 'on_chain',
+//	  block_number = $2, block_hash = $3, tx_hash = $4,
+//	  versioned_hash = $5, blob_index = $6, blob_position = $7
+//	WHERE id = (
+//	  SELECT pending.id FROM message.blob pending
+//	  WHERE pending.index = $8 AND pending.message = $9 AND pending.block_number IS NULL
+//	  ORDER BY pending.id LIMIT 1
+//	)
+func (q *Queries) ConfirmMessage(ctx context.Context, arg ConfirmMessageParams) (int64, error) {
+	result, err := q.db.Exec(ctx, confirmMessage,
+		arg.SubmitTime,
+		arg.BlockNumber,
+		arg.BlockHash,
+		arg.TxHash,
+		arg.VersionedHash,
+		arg.BlobIndex,
+		arg.BlobPosition,
+		arg.Index,
+		arg.Message,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const failBlobSubmission = `-- name: FailBlobSubmission :exec
+UPDATE message.blob_submission SET state = 'failed', error = $2, updated_at = NOW() WHERE id = $1
+`
+
+type FailBlobSubmissionParams struct {
+	ID    int32
+	Error *string
+}
+
+// FailBlobSubmission
+//
+//	UPDATE message.blob_submission SET state = 'failed', error = $2, updated_at = NOW() WHERE id = $1
+func (q *Queries) FailBlobSubmission(ctx context.Context, arg FailBlobSubmissionParams) error {
+	_, err := q.db.Exec(ctx, failBlobSubmission, arg.ID, arg.Error)
+	return err
+}
+
+const finalizeBlobSubmissions = `-- name: FinalizeBlobSubmissions :exec
+UPDATE message.blob_submission SET state = 'finalized', updated_at = NOW()
+WHERE state = 'confirmed' AND block_number <= $1
+`
+
+// FinalizeBlobSubmissions
+//
+//	UPDATE message.blob_submission SET state = 'finalized', updated_at = NOW()
+//	WHERE state = 'confirmed' AND block_number <= $1
+func (q *Queries) FinalizeBlobSubmissions(ctx context.Context, blockNumber *int64) error {
+	_, err := q.db.Exec(ctx, finalizeBlobSubmissions, blockNumber)
+	return err
+}
+
+const finalizeMessages = `-- name: FinalizeMessages :exec
+UPDATE message.blob SET state = 'finalized' WHERE state = 'on_chain' AND block_number <= $1
+`
+
+// FinalizeMessages
+//
+//	UPDATE message.blob SET state = 'finalized' WHERE state = 'on_chain' AND block_number <= $1
+func (q *Queries) FinalizeMessages(ctx context.Context, blockNumber *int64) error {
+	_, err := q.db.Exec(ctx, finalizeMessages, blockNumber)
+	return err
+}
+
+const getBlobBatch = `-- name: GetBlobBatch :one
+SELECT id, versioned_hash, nonce, message_count, fill_ratio, wait_ms, trigger, created_at FROM message.blob_batch WHERE versioned_hash = $1 ORDER BY id DESC LIMIT 1
+`
+
+// GetBlobBatch
+//
+//	SELECT id, versioned_hash, nonce, message_count, fill_ratio, wait_ms, trigger, created_at FROM message.blob_batch WHERE versioned_hash = $1 ORDER BY id DESC LIMIT 1
+func (q *Queries) GetBlobBatch(ctx context.Context, versionedHash []byte) (MessageBlobBatch, error) {
+	row := q.db.QueryRow(ctx, getBlobBatch, versionedHash)
+	var i MessageBlobBatch
+	err := row.Scan(
+		&i.ID,
+		&i.VersionedHash,
+		&i.Nonce,
+		&i.MessageCount,
+		&i.FillRatio,
+		&i.WaitMs,
+		&i.Trigger,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getBlobFeesSince = `-- name: GetBlobFeesSince :one
+SELECT COALESCE(SUM(fee), 0)::BIGINT AS total FROM message.blob_fee WHERE submit_time > $1
+`
+
+// GetBlobFeesSince
+//
+//	SELECT COALESCE(SUM(fee), 0)::BIGINT AS total FROM message.blob_fee WHERE submit_time > $1
+func (q *Queries) GetBlobFeesSince(ctx context.Context, submitTime time.Time) (int64, error) {
+	row := q.db.QueryRow(ctx, getBlobFeesSince, submitTime)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const getBlobSubmission = `-- name: GetBlobSubmission :one
+SELECT id, index, message, pubkey, state, nonce, tx_hash, error, updated_at, versioned_hash, block_number, queued_at FROM message.blob_submission WHERE id = $1
+`
+
+// GetBlobSubmission
+//
+//	SELECT id, index, message, pubkey, state, nonce, tx_hash, error, updated_at, versioned_hash, block_number, queued_at FROM message.blob_submission WHERE id = $1
+func (q *Queries) GetBlobSubmission(ctx context.Context, id int32) (MessageBlobSubmission, error) {
+	row := q.db.QueryRow(ctx, getBlobSubmission, id)
+	var i MessageBlobSubmission
+	err := row.Scan(
+		&i.ID,
+		&i.Index,
+		&i.Message,
+		&i.Pubkey,
+		&i.State,
+		&i.Nonce,
+		&i.TxHash,
+		&i.Error,
+		&i.UpdatedAt,
+		&i.VersionedHash,
+		&i.BlockNumber,
+		&i.QueuedAt,
+	)
+	return i, err
+}
+
+const getBlobTxCarriers = `-- name: GetBlobTxCarriers :many
+SELECT DISTINCT carrier FROM message.blob_tx
+`
+
+// GetBlobTxCarriers
+//
+//	SELECT DISTINCT carrier FROM message.blob_tx
+func (q *Queries) GetBlobTxCarriers(ctx context.Context) ([]MessageTxCarrier, error) {
+	rows, err := q.db.Query(ctx, getBlobTxCarriers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageTxCarrier
+	for rows.Next() {
+		var carrier MessageTxCarrier
+		if err := rows.Scan(&carrier); err != nil {
+			return nil, err
+		}
+		items = append(items, carrier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBlobTxs = `-- name: GetBlobTxs :many
+SELECT nonce, tx_hashes, payloads, gas_tip_cap, gas_fee_cap, blob_fee_cap, sent_at, carrier FROM message.blob_tx ORDER BY nonce
+`
+
+// GetBlobTxs
+//
+//	SELECT nonce, tx_hashes, payloads, gas_tip_cap, gas_fee_cap, blob_fee_cap, sent_at, carrier FROM message.blob_tx ORDER BY nonce
+func (q *Queries) GetBlobTxs(ctx context.Context) ([]MessageBlobTx, error) {
+	rows, err := q.db.Query(ctx, getBlobTxs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageBlobTx
+	for rows.Next() {
+		var i MessageBlobTx
+		if err := rows.Scan(
+			&i.Nonce,
+			&i.TxHashes,
+			&i.Payloads,
+			&i.GasTipCap,
+			&i.GasFeeCap,
+			&i.BlobFeeCap,
+			&i.SentAt,
+			&i.Carrier,
 		); err != nil {
 			return nil, err
 		}
@@ -144,17 +657,58 @@ func (q *Queries) GetBlobSubmissions(ctx context.Context) ([]MessageBlobSubmissi
 }
 
 const getBlobUpdate = `-- name: GetBlobUpdate :one
-SELECT block_height FROM message.blob_update LIMIT 1
+SELECT block_height, blob_index FROM message.blob_update LIMIT 1
 `
 
+type GetBlobUpdateRow struct {
+	BlockHeight int64
+	BlobIndex   int32
+}
+
 // GetBlobUpdate
 //
-//	SELECT block_height FROM message.blob_update LIMIT 1
-func (q *Queries) GetBlobUpdate(ctx context.Context) (int64, error) {
+//	SELECT block_height, blob_index FROM message.blob_update LIMIT 1
+func (q *Queries) GetBlobUpdate(ctx context.Context) (GetBlobUpdateRow, error) {
 	row := q.db.QueryRow(ctx, getBlobUpdate)
-	var block_height int64
-	err := row.Scan(&block_height)
-	return block_height, err
+	var i GetBlobUpdateRow
+	err := row.Scan(&i.BlockHeight, &i.BlobIndex)
+	return i, err
+}
+
+const getDueFailedBlobs = `-- name: GetDueFailedBlobs :many
+SELECT versioned_hash, block_number, blob_index, error, attempts, next_attempt_at, created_at, updated_at FROM message.failed_blob WHERE next_attempt_at <= $1 ORDER BY next_attempt_at LIMIT 50
+`
+
+// GetDueFailedBlobs
+//
+//	SELECT versioned_hash, block_number, blob_index, error, attempts, next_attempt_at, created_at, updated_at FROM message.failed_blob WHERE next_attempt_at <= $1 ORDER BY next_attempt_at LIMIT 50
+func (q *Queries) GetDueFailedBlobs(ctx context.Context, nextAttemptAt time.Time) ([]MessageFailedBlob, error) {
+	rows, err := q.db.Query(ctx, getDueFailedBlobs, nextAttemptAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageFailedBlob
+	for rows.Next() {
+		var i MessageFailedBlob
+		if err := rows.Scan(
+			&i.VersionedHash,
+			&i.BlockNumber,
+			&i.BlobIndex,
+			&i.Error,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getENSSubdomainByAddress = `-- name: GetENSSubdomainByAddress :one
@@ -172,14 +726,79 @@ func (q *Queries) GetENSSubdomainByAddress(ctx context.Context, address string)
 }
 
 const getMessagesByIndex = `-- name: GetMessagesByIndex :many
-SELECT id, index, message, submit_time, needs_submission FROM message.blob WHERE index = $1
+SELECT id, index, message, submit_time, block_number, block_hash, tx_hash, versioned_hash, blob_position, state, submission_id, blob_index FROM message.blob WHERE index = $1 AND state::TEXT = ANY($4::TEXT[])
+ORDER BY block_number NULLS LAST, blob_index, blob_position, id
+LIMIT $2 OFFSET $3
 `
 
+type GetMessagesByIndexParams struct {
+	Index  []byte
+	Limit  int32
+	Offset int32
+	States []string
+}
+
 // GetMessagesByIndex
 //
-//	SELECT id, index, message, submit_time, needs_submission FROM message.blob WHERE index = $1
-func (q *Queries) GetMessagesByIndex(ctx context.Context, index []byte) ([]MessageBlob, error) {
-	rows, err := q.db.Query(ctx, getMessagesByIndex, index)
+//	SELECT id, index, message, submit_time, block_number, block_hash, tx_hash, versioned_hash, blob_position, state, submission_id, blob_index FROM message.blob WHERE index = $1 AND state::TEXT = ANY($4::TEXT[])
+//	ORDER BY block_number NULLS LAST, blob_index, blob_position, id
+//	LIMIT $2 OFFSET $3
+func (q *Queries) GetMessagesByIndex(ctx context.Context, arg GetMessagesByIndexParams) ([]MessageBlob, error) {
+	rows, err := q.db.Query(ctx, getMessagesByIndex,
+		arg.Index,
+		arg.Limit,
+		arg.Offset,
+		arg.States,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageBlob
+	for rows.Next() {
+		var i MessageBlob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Index,
+			&i.Message,
+			&i.SubmitTime,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.TxHash,
+			&i.VersionedHash,
+			&i.BlobPosition,
+			&i.State,
+			&i.SubmissionID,
+			&i.BlobIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMessagesByIndexes = `-- name: GetMessagesByIndexes :many
+SELECT id, index, message, submit_time, block_number, block_hash, tx_hash, versioned_hash, blob_position, state, submission_id, blob_index FROM message.blob
+WHERE index = ANY($1::BYTEA[]) AND state::TEXT = ANY($2::TEXT[])
+ORDER BY index, block_number NULLS LAST, blob_index, blob_position, id
+`
+
+type GetMessagesByIndexesParams struct {
+	Indexes [][]byte
+	States  []string
+}
+
+// GetMessagesByIndexes
+//
+//	SELECT id, index, message, submit_time, block_number, block_hash, tx_hash, versioned_hash, blob_position, state, submission_id, blob_index FROM message.blob
+//	WHERE index = ANY($1::BYTEA[]) AND state::TEXT = ANY($2::TEXT[])
+//	ORDER BY index, block_number NULLS LAST, blob_index, blob_position, id
+func (q *Queries) GetMessagesByIndexes(ctx context.Context, arg GetMessagesByIndexesParams) ([]MessageBlob, error) {
+	rows, err := q.db.Query(ctx, getMessagesByIndexes, arg.Indexes, arg.States)
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +811,14 @@ func (q *Queries) GetMessagesByIndex(ctx context.Context, index []byte) ([]Messa
 			&i.Index,
 			&i.Message,
 			&i.SubmitTime,
-			&i.NeedsSubmission,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.TxHash,
+			&i.VersionedHash,
+			&i.BlobPosition,
+			&i.State,
+			&i.SubmissionID,
+			&i.BlobIndex,
 		); err != nil {
 			return nil, err
 		}
@@ -204,15 +830,89 @@ func (q *Queries) GetMessagesByIndex(ctx context.Context, index []byte) ([]Messa
 	return items, nil
 }
 
-const getPubkeysSince = `-- name: GetPubkeysSince :many
-SELECT pubkey, submit_time FROM message.pubkey WHERE submit_time > $1 LIMIT 1000
+const getNextBlobTxNonce = `-- name: GetNextBlobTxNonce :one
+SELECT COALESCE(MAX(nonce) + 1, 0)::BIGINT AS nonce FROM message.blob_tx
 `
 
-// GetPubkeysSince
+// GetNextBlobTxNonce
 //
-//	SELECT pubkey, submit_time FROM message.pubkey WHERE submit_time > $1 LIMIT 1000
-func (q *Queries) GetPubkeysSince(ctx context.Context, submitTime time.Time) ([]MessagePubkey, error) {
-	rows, err := q.db.Query(ctx, getPubkeysSince, submitTime)
+//	SELECT COALESCE(MAX(nonce) + 1, 0)::BIGINT AS nonce FROM message.blob_tx
+func (q *Queries) GetNextBlobTxNonce(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, getNextBlobTxNonce)
+	var nonce int64
+	err := row.Scan(&nonce)
+	return nonce, err
+}
+
+const getPendingBackfillRanges = `-- name: GetPendingBackfillRanges :many
+SELECT from_block, to_block, completed_at FROM message.backfill_range
+WHERE completed_at IS NULL AND from_block >= $1 AND to_block <= $2
+ORDER BY from_block
+`
+
+type GetPendingBackfillRangesParams struct {
+	FromBlock int64
+	ToBlock   int64
+}
+
+// GetPendingBackfillRanges
+//
+//	SELECT from_block, to_block, completed_at FROM message.backfill_range
+//	WHERE completed_at IS NULL AND from_block >= $1 AND to_block <= $2
+//	ORDER BY from_block
+func (q *Queries) GetPendingBackfillRanges(ctx context.Context, arg GetPendingBackfillRangesParams) ([]MessageBackfillRange, error) {
+	rows, err := q.db.Query(ctx, getPendingBackfillRanges, arg.FromBlock, arg.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageBackfillRange
+	for rows.Next() {
+		var i MessageBackfillRange
+		if err := rows.Scan(&i.FromBlock, &i.ToBlock, &i.CompletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPubkeysPage = `-- name: GetPubkeysPage :many
+SELECT pubkey, submit_time, block_number FROM message.pubkey
+WHERE (submit_time, pubkey) > ($1, $2::BYTEA)
+  AND ($3::TIMESTAMP IS NULL OR submit_time > $3)
+  AND ($4::BIGINT IS NULL OR block_number >= $4)
+ORDER BY submit_time, pubkey
+LIMIT $5
+`
+
+type GetPubkeysPageParams struct {
+	AfterTime   time.Time
+	AfterPubkey []byte
+	Since       *time.Time
+	FromBlock   *int64
+	PageSize    int32
+}
+
+// GetPubkeysPage
+//
+//	SELECT pubkey, submit_time, block_number FROM message.pubkey
+//	WHERE (submit_time, pubkey) > ($1, $2::BYTEA)
+//	  AND ($3::TIMESTAMP IS NULL OR submit_time > $3)
+//	  AND ($4::BIGINT IS NULL OR block_number >= $4)
+//	ORDER BY submit_time, pubkey
+//	LIMIT $5
+func (q *Queries) GetPubkeysPage(ctx context.Context, arg GetPubkeysPageParams) ([]MessagePubkey, error) {
+	rows, err := q.db.Query(ctx, getPubkeysPage,
+		arg.AfterTime,
+		arg.AfterPubkey,
+		arg.Since,
+		arg.FromBlock,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +920,7 @@ func (q *Queries) GetPubkeysSince(ctx context.Context, submitTime time.Time) ([]
 	var items []MessagePubkey
 	for rows.Next() {
 		var i MessagePubkey
-		if err := rows.Scan(&i.Pubkey, &i.SubmitTime); err != nil {
+		if err := rows.Scan(&i.Pubkey, &i.SubmitTime, &i.BlockNumber); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -231,15 +931,307 @@ func (q *Queries) GetPubkeysSince(ctx context.Context, submitTime time.Time) ([]
 	return items, nil
 }
 
-const removeBlobSubmission = `-- name: RemoveBlobSubmission :exec
-DELETE FROM message.blob_submission WHERE id = $1
+const getRecentChainBlocks = `-- name: GetRecentChainBlocks :many
+SELECT block_number, block_hash, parent_hash FROM message.chain_block ORDER BY block_number DESC LIMIT $1
+`
+
+// GetRecentChainBlocks
+//
+//	SELECT block_number, block_hash, parent_hash FROM message.chain_block ORDER BY block_number DESC LIMIT $1
+func (q *Queries) GetRecentChainBlocks(ctx context.Context, limit int32) ([]MessageChainBlock, error) {
+	rows, err := q.db.Query(ctx, getRecentChainBlocks, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageChainBlock
+	for rows.Next() {
+		var i MessageChainBlock
+		if err := rows.Scan(&i.BlockNumber, &i.BlockHash, &i.ParentHash); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFailedBlobs = `-- name: ListFailedBlobs :many
+SELECT versioned_hash, block_number, blob_index, error, attempts, next_attempt_at, created_at, updated_at FROM message.failed_blob ORDER BY block_number, blob_index LIMIT $1 OFFSET $2
+`
+
+type ListFailedBlobsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+// ListFailedBlobs
+//
+//	SELECT versioned_hash, block_number, blob_index, error, attempts, next_attempt_at, created_at, updated_at FROM message.failed_blob ORDER BY block_number, blob_index LIMIT $1 OFFSET $2
+func (q *Queries) ListFailedBlobs(ctx context.Context, arg ListFailedBlobsParams) ([]MessageFailedBlob, error) {
+	rows, err := q.db.Query(ctx, listFailedBlobs, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageFailedBlob
+	for rows.Next() {
+		var i MessageFailedBlob
+		if err := rows.Scan(
+			&i.VersionedHash,
+			&i.BlockNumber,
+			&i.BlobIndex,
+			&i.Error,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markBlobSubmissionsInFlight = `-- name: MarkBlobSubmissionsInFlight :exec
+UPDATE message.blob_submission SET state = 'in_flight', nonce = $1, tx_hash = $2, versioned_hash = $3, updated_at = NOW()
+WHERE id = ANY($4::INT[])
+`
+
+type MarkBlobSubmissionsInFlightParams struct {
+	Nonce         *int64
+	TxHash        []byte
+	VersionedHash []byte
+	Ids           []int32
+}
+
+// MarkBlobSubmissionsInFlight
+//
+//	UPDATE message.blob_submission SET state = 'in_flight', nonce = $1, tx_hash = $2, versioned_hash = $3, updated_at = NOW()
+//	WHERE id = ANY($4::INT[])
+func (q *Queries) MarkBlobSubmissionsInFlight(ctx context.Context, arg MarkBlobSubmissionsInFlightParams) error {
+	_, err := q.db.Exec(ctx, markBlobSubmissionsInFlight,
+		arg.Nonce,
+		arg.TxHash,
+		arg.VersionedHash,
+		arg.Ids,
+	)
+	return err
+}
+
+const markMessagesSubmitted = `-- name: MarkMessagesSubmitted :exec
+UPDATE message.blob SET state = 'submitted' WHERE submission_id = ANY($1::INT[]) AND state = 'pending'
+`
+
+// MarkMessagesSubmitted
+//
+//	UPDATE message.blob SET state = 'submitted' WHERE submission_id = ANY($1::INT[]) AND state = 'pending'
+func (q *Queries) MarkMessagesSubmitted(ctx context.Context, submissionIds []int32) error {
+	_, err := q.db.Exec(ctx, markMessagesSubmitted, submissionIds)
+	return err
+}
+
+const publishBlobSubmissions = `-- name: PublishBlobSubmissions :exec
+UPDATE message.blob_submission SET state = 'confirmed', versioned_hash = $1, updated_at = NOW()
+WHERE id = ANY($2::INT[])
+`
+
+type PublishBlobSubmissionsParams struct {
+	VersionedHash []byte
+	Ids           []int32
+}
+
+// PublishBlobSubmissions
+//
+//	UPDATE message.blob_submission SET state = 'confirmed', versioned_hash = $1, updated_at = NOW()
+//	WHERE id = ANY($2::INT[])
+func (q *Queries) PublishBlobSubmissions(ctx context.Context, arg PublishBlobSubmissionsParams) error {
+	_, err := q.db.Exec(ctx, publishBlobSubmissions, arg.VersionedHash, arg.Ids)
+	return err
+}
+
+const removeBlobTx = `-- name: RemoveBlobTx :exec
+DELETE FROM message.blob_tx WHERE nonce = $1
+`
+
+// RemoveBlobTx
+//
+//	DELETE FROM message.blob_tx WHERE nonce = $1
+func (q *Queries) RemoveBlobTx(ctx context.Context, nonce int64) error {
+	_, err := q.db.Exec(ctx, removeBlobTx, nonce)
+	return err
+}
+
+const removeChainBlocksBefore = `-- name: RemoveChainBlocksBefore :exec
+DELETE FROM message.chain_block WHERE block_number < $1
+`
+
+// RemoveChainBlocksBefore
+//
+//	DELETE FROM message.chain_block WHERE block_number < $1
+func (q *Queries) RemoveChainBlocksBefore(ctx context.Context, blockNumber int64) error {
+	_, err := q.db.Exec(ctx, removeChainBlocksBefore, blockNumber)
+	return err
+}
+
+const removeChainBlocksFrom = `-- name: RemoveChainBlocksFrom :exec
+DELETE FROM message.chain_block WHERE block_number >= $1
 `
 
-// RemoveBlobSubmission
+// RemoveChainBlocksFrom
 //
-//	DELETE FROM message.blob_submission WHERE id = $1
-func (q *Queries) RemoveBlobSubmission(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, removeBlobSubmission, id)
+//	DELETE FROM message.chain_block WHERE block_number >= $1
+func (q *Queries) RemoveChainBlocksFrom(ctx context.Context, blockNumber int64) error {
+	_, err := q.db.Exec(ctx, removeChainBlocksFrom, blockNumber)
+	return err
+}
+
+const removeFailedBlob = `-- name: RemoveFailedBlob :exec
+DELETE FROM message.failed_blob WHERE versioned_hash = $1
+`
+
+// RemoveFailedBlob
+//
+//	DELETE FROM message.failed_blob WHERE versioned_hash = $1
+func (q *Queries) RemoveFailedBlob(ctx context.Context, versionedHash []byte) error {
+	_, err := q.db.Exec(ctx, removeFailedBlob, versionedHash)
+	return err
+}
+
+const removeFailedBlobsFromBlock = `-- name: RemoveFailedBlobsFromBlock :exec
+DELETE FROM message.failed_blob WHERE block_number >= $1
+`
+
+// RemoveFailedBlobsFromBlock
+//
+//	DELETE FROM message.failed_blob WHERE block_number >= $1
+func (q *Queries) RemoveFailedBlobsFromBlock(ctx context.Context, blockNumber int64) error {
+	_, err := q.db.Exec(ctx, removeFailedBlobsFromBlock, blockNumber)
+	return err
+}
+
+const removeIngestedBlobsFromBlock = `-- name: RemoveIngestedBlobsFromBlock :exec
+DELETE FROM message.ingested_blob WHERE block_number >= $1
+`
+
+// RemoveIngestedBlobsFromBlock
+//
+//	DELETE FROM message.ingested_blob WHERE block_number >= $1
+func (q *Queries) RemoveIngestedBlobsFromBlock(ctx context.Context, blockNumber int64) error {
+	_, err := q.db.Exec(ctx, removeIngestedBlobsFromBlock, blockNumber)
+	return err
+}
+
+const removeMessagesFromBlock = `-- name: RemoveMessagesFromBlock :exec
+DELETE FROM message.blob WHERE block_number >= $1
+`
+
+// RemoveMessagesFromBlock
+//
+//	DELETE FROM message.blob WHERE block_number >= $1
+func (q *Queries) RemoveMessagesFromBlock(ctx context.Context, blockNumber *int64) error {
+	_, err := q.db.Exec(ctx, removeMessagesFromBlock, blockNumber)
+	return err
+}
+
+const removePendingMessages = `-- name: RemovePendingMessages :exec
+DELETE FROM message.blob WHERE submission_id = $1 AND state = 'pending'
+`
+
+// RemovePendingMessages
+//
+//	DELETE FROM message.blob WHERE submission_id = $1 AND state = 'pending'
+func (q *Queries) RemovePendingMessages(ctx context.Context, submissionID *int32) error {
+	_, err := q.db.Exec(ctx, removePendingMessages, submissionID)
+	return err
+}
+
+const requeueBlobSubmissions = `-- name: RequeueBlobSubmissions :exec
+UPDATE message.blob_submission SET state = 'queued', nonce = NULL, tx_hash = NULL, versioned_hash = NULL, updated_at = NOW()
+WHERE nonce = $1 AND state = 'in_flight'
+`
+
+// RequeueBlobSubmissions
+//
+//	UPDATE message.blob_submission SET state = 'queued', nonce = NULL, tx_hash = NULL, versioned_hash = NULL, updated_at = NOW()
+//	WHERE nonce = $1 AND state = 'in_flight'
+func (q *Queries) RequeueBlobSubmissions(ctx context.Context, nonce *int64) error {
+	_, err := q.db.Exec(ctx, requeueBlobSubmissions, nonce)
+	return err
+}
+
+const requeueMessages = `-- name: RequeueMessages :exec
+UPDATE message.blob SET state = 'pending'
+WHERE state = 'submitted' AND submission_id IN (
+  SELECT id FROM message.blob_submission WHERE nonce = $1 AND state = 'in_flight'
+)
+`
+
+// RequeueMessages
+//
+//	UPDATE message.blob SET state = 'pending'
+//	WHERE state = 'submitted' AND submission_id IN (
+//	  SELECT id FROM message.blob_submission WHERE nonce = $1 AND state = 'in_flight'
+//	)
+func (q *Queries) RequeueMessages(ctx context.Context, nonce *int64) error {
+	_, err := q.db.Exec(ctx, requeueMessages, nonce)
+	return err
+}
+
+const retryFailedBlob = `-- name: RetryFailedBlob :exec
+UPDATE message.failed_blob SET error = $2, attempts = attempts + 1, next_attempt_at = $3, updated_at = NOW()
+WHERE versioned_hash = $1
+`
+
+type RetryFailedBlobParams struct {
+	VersionedHash []byte
+	Error         string
+	NextAttemptAt time.Time
+}
+
+// RetryFailedBlob
+//
+//	UPDATE message.failed_blob SET error = $2, attempts = attempts + 1, next_attempt_at = $3, updated_at = NOW()
+//	WHERE versioned_hash = $1
+func (q *Queries) RetryFailedBlob(ctx context.Context, arg RetryFailedBlobParams) error {
+	_, err := q.db.Exec(ctx, retryFailedBlob, arg.VersionedHash, arg.Error, arg.NextAttemptAt)
+	return err
+}
+
+const rewindBlobUpdate = `-- name: RewindBlobUpdate :exec
+UPDATE message.blob_update SET block_height = LEAST(block_height, $1),
+  blob_index = CASE WHEN block_height >= $1 THEN 0 ELSE blob_index END
+`
+
+// RewindBlobUpdate
+//
+//	UPDATE message.blob_update SET block_height = LEAST(block_height, $1),
+//	  blob_index = CASE WHEN block_height >= $1 THEN 0 ELSE blob_index END
+func (q *Queries) RewindBlobUpdate(ctx context.Context, blockHeight int64) error {
+	_, err := q.db.Exec(ctx, rewindBlobUpdate, blockHeight)
+	return err
+}
+
+const setBlobSubmissionsTxHash = `-- name: SetBlobSubmissionsTxHash :exec
+UPDATE message.blob_submission SET tx_hash = $2, updated_at = NOW() WHERE nonce = $1 AND state = 'in_flight'
+`
+
+type SetBlobSubmissionsTxHashParams struct {
+	Nonce  *int64
+	TxHash []byte
+}
+
+// SetBlobSubmissionsTxHash
+//
+//	UPDATE message.blob_submission SET tx_hash = $2, updated_at = NOW() WHERE nonce = $1 AND state = 'in_flight'
+func (q *Queries) SetBlobSubmissionsTxHash(ctx context.Context, arg SetBlobSubmissionsTxHashParams) error {
+	_, err := q.db.Exec(ctx, setBlobSubmissionsTxHash, arg.Nonce, arg.TxHash)
 	return err
 }
 
@@ -255,14 +1247,77 @@ func (q *Queries) SetBlobUpdate(ctx context.Context, blockHeight int64) error {
 	return err
 }
 
+const setFinalizedBlock = `-- name: SetFinalizedBlock :exec
+UPDATE message.blob_update SET finalized_block = $1
+`
+
+// SetFinalizedBlock
+//
+//	UPDATE message.blob_update SET finalized_block = $1
+func (q *Queries) SetFinalizedBlock(ctx context.Context, finalizedBlock int64) error {
+	_, err := q.db.Exec(ctx, setFinalizedBlock, finalizedBlock)
+	return err
+}
+
+const unconfirmMessagesFromBlock = `-- name: UnconfirmMessagesFromBlock :exec
+UPDATE message.blob SET state = 'submitted', block_number = NULL, block_hash = NULL, tx_hash = NULL,
+  versioned_hash = NULL, blob_index = NULL, blob_position = NULL
+WHERE block_number >= $1 AND submission_id IS NOT NULL
+`
+
+// UnconfirmMessagesFromBlock
+//
+//	UPDATE message.blob SET state = 'submitted', block_number = NULL, block_hash = NULL, tx_hash = NULL,
+//	  versioned_hash = NULL, blob_index = NULL, blob_position = NULL
+//	WHERE block_number >= $1 AND submission_id IS NOT NULL
+func (q *Queries) UnconfirmMessagesFromBlock(ctx context.Context, blockNumber *int64) error {
+	_, err := q.db.Exec(ctx, unconfirmMessagesFromBlock, blockNumber)
+	return err
+}
+
+const updateBlobTx = `-- name: UpdateBlobTx :exec
+UPDATE message.blob_tx SET tx_hashes = $2, gas_tip_cap = $3, gas_fee_cap = $4, blob_fee_cap = $5, sent_at = $6
+WHERE nonce = $1
+`
+
+type UpdateBlobTxParams struct {
+	Nonce      int64
+	TxHashes   [][]byte
+	GasTipCap  int64
+	GasFeeCap  int64
+	BlobFeeCap int64
+	SentAt     time.Time
+}
+
+// UpdateBlobTx
+//
+//	UPDATE message.blob_tx SET tx_hashes = $2, gas_tip_cap = $3, gas_fee_cap = $4, blob_fee_cap = $5, sent_at = $6
+//	WHERE nonce = $1
+func (q *Queries) UpdateBlobTx(ctx context.Context, arg UpdateBlobTxParams) error {
+	_, err := q.db.Exec(ctx, updateBlobTx,
+		arg.Nonce,
+		arg.TxHashes,
+		arg.GasTipCap,
+		arg.GasFeeCap,
+		arg.BlobFeeCap,
+		arg.SentAt,
+	)
+	return err
+}
+
 const updateBlobUpdate = `-- name: UpdateBlobUpdate :exec
-UPDATE message.blob_update SET block_height = $1
+UPDATE message.blob_update SET block_height = $1, blob_index = $2
 `
 
+type UpdateBlobUpdateParams struct {
+	BlockHeight int64
+	BlobIndex   int32
+}
+
 // UpdateBlobUpdate
 //
-//	UPDATE message.blob_update SET block_height = $1
-func (q *Queries) UpdateBlobUpdate(ctx context.Context, blockHeight int64) error {
-	_, err := q.db.Exec(ctx, updateBlobUpdate, blockHeight)
+//	UPDATE message.blob_update SET block_height = $1, blob_index = $2
+func (q *Queries) UpdateBlobUpdate(ctx context.Context, arg UpdateBlobUpdateParams) error {
+	_, err := q.db.Exec(ctx, updateBlobUpdate, arg.BlockHeight, arg.BlobIndex)
 	return err
 }
@@ -5,26 +5,335 @@
 package dbgen
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"time"
 )
 
+type MessageBlobState string
+
+const (
+	MessageBlobStatePending   MessageBlobState = "pending"
+	MessageBlobStateSubmitted MessageBlobState = "submitted"
+	MessageBlobStateOnChain   MessageBlobState = "on_chain"
+	MessageBlobStateFinalized MessageBlobState = "finalized"
+)
+
+func (e *MessageBlobState) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MessageBlobState(s)
+	case string:
+		*e = MessageBlobState(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MessageBlobState: %T", src)
+	}
+	return nil
+}
+
+type NullMessageBlobState struct {
+	MessageBlobState MessageBlobState
+	Valid            bool // Valid is true if MessageBlobState is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMessageBlobState) Scan(value interface{}) error {
+	if value == nil {
+		ns.MessageBlobState, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MessageBlobState.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMessageBlobState) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MessageBlobState), nil
+}
+
+func (e MessageBlobState) Valid() bool {
+	switch e {
+	case MessageBlobStatePending,
+		MessageBlobStateSubmitted,
+		MessageBlobStateOnChain,
+		MessageBlobStateFinalized:
+		return true
+	}
+	return false
+}
+
+func AllMessageBlobStateValues() []MessageBlobState {
+	return []MessageBlobState{
+		MessageBlobStatePending,
+		MessageBlobStateSubmitted,
+		MessageBlobStateOnChain,
+		MessageBlobStateFinalized,
+	}
+}
+
+type MessageFlushTrigger string
+
+const (
+	MessageFlushTriggerFill     MessageFlushTrigger = "fill"
+	MessageFlushTriggerDeadline MessageFlushTrigger = "deadline"
+	MessageFlushTriggerFee      MessageFlushTrigger = "fee"
+)
+
+func (e *MessageFlushTrigger) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MessageFlushTrigger(s)
+	case string:
+		*e = MessageFlushTrigger(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MessageFlushTrigger: %T", src)
+	}
+	return nil
+}
+
+type NullMessageFlushTrigger struct {
+	MessageFlushTrigger MessageFlushTrigger
+	Valid               bool // Valid is true if MessageFlushTrigger is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMessageFlushTrigger) Scan(value interface{}) error {
+	if value == nil {
+		ns.MessageFlushTrigger, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MessageFlushTrigger.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMessageFlushTrigger) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MessageFlushTrigger), nil
+}
+
+func (e MessageFlushTrigger) Valid() bool {
+	switch e {
+	case MessageFlushTriggerFill,
+		MessageFlushTriggerDeadline,
+		MessageFlushTriggerFee:
+		return true
+	}
+	return false
+}
+
+func AllMessageFlushTriggerValues() []MessageFlushTrigger {
+	return []MessageFlushTrigger{
+		MessageFlushTriggerFill,
+		MessageFlushTriggerDeadline,
+		MessageFlushTriggerFee,
+	}
+}
+
+type MessageSubmissionState string
+
+const (
+	MessageSubmissionStateQueued    MessageSubmissionState = "queued"
+	MessageSubmissionStateInFlight  MessageSubmissionState = "in_flight"
+	MessageSubmissionStateConfirmed MessageSubmissionState = "confirmed"
+	MessageSubmissionStateFinalized MessageSubmissionState = "finalized"
+	MessageSubmissionStateFailed    MessageSubmissionState = "failed"
+)
+
+func (e *MessageSubmissionState) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MessageSubmissionState(s)
+	case string:
+		*e = MessageSubmissionState(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MessageSubmissionState: %T", src)
+	}
+	return nil
+}
+
+type NullMessageSubmissionState struct {
+	MessageSubmissionState MessageSubmissionState
+	Valid                  bool // Valid is true if MessageSubmissionState is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMessageSubmissionState) Scan(value interface{}) error {
+	if value == nil {
+		ns.MessageSubmissionState, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MessageSubmissionState.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMessageSubmissionState) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MessageSubmissionState), nil
+}
+
+func (e MessageSubmissionState) Valid() bool {
+	switch e {
+	case MessageSubmissionStateQueued,
+		MessageSubmissionStateInFlight,
+		MessageSubmissionStateConfirmed,
+		MessageSubmissionStateFinalized,
+		MessageSubmissionStateFailed:
+		return true
+	}
+	return false
+}
+
+func AllMessageSubmissionStateValues() []MessageSubmissionState {
+	return []MessageSubmissionState{
+		MessageSubmissionStateQueued,
+		MessageSubmissionStateInFlight,
+		MessageSubmissionStateConfirmed,
+		MessageSubmissionStateFinalized,
+		MessageSubmissionStateFailed,
+	}
+}
+
+type MessageTxCarrier string
+
+const (
+	MessageTxCarrierBlob     MessageTxCarrier = "blob"
+	MessageTxCarrierCalldata MessageTxCarrier = "calldata"
+)
+
+func (e *MessageTxCarrier) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MessageTxCarrier(s)
+	case string:
+		*e = MessageTxCarrier(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MessageTxCarrier: %T", src)
+	}
+	return nil
+}
+
+type NullMessageTxCarrier struct {
+	MessageTxCarrier MessageTxCarrier
+	Valid            bool // Valid is true if MessageTxCarrier is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMessageTxCarrier) Scan(value interface{}) error {
+	if value == nil {
+		ns.MessageTxCarrier, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MessageTxCarrier.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMessageTxCarrier) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MessageTxCarrier), nil
+}
+
+func (e MessageTxCarrier) Valid() bool {
+	switch e {
+	case MessageTxCarrierBlob,
+		MessageTxCarrierCalldata:
+		return true
+	}
+	return false
+}
+
+func AllMessageTxCarrierValues() []MessageTxCarrier {
+	return []MessageTxCarrier{
+		MessageTxCarrierBlob,
+		MessageTxCarrierCalldata,
+	}
+}
+
+type MessageBackfillRange struct {
+	FromBlock   int64
+	ToBlock     int64
+	CompletedAt *time.Time
+}
+
 type MessageBlob struct {
-	ID              int32
-	Index           []byte
-	Message         []byte
-	SubmitTime      time.Time
-	NeedsSubmission bool
+	ID            int32
+	Index         []byte
+	Message       []byte
+	SubmitTime    time.Time
+	BlockNumber   *int64
+	BlockHash     []byte
+	TxHash        []byte
+	VersionedHash []byte
+	BlobPosition  *int32
+	State         MessageBlobState
+	SubmissionID  *int32
+	BlobIndex     *int32
+}
+
+type MessageBlobBatch struct {
+	ID            int32
+	VersionedHash []byte
+	Nonce         *int64
+	MessageCount  int32
+	FillRatio     float64
+	WaitMs        int64
+	Trigger       MessageFlushTrigger
+	CreatedAt     time.Time
+}
+
+type MessageBlobFee struct {
+	TxHash     []byte
+	Fee        int64
+	SubmitTime time.Time
 }
 
 type MessageBlobSubmission struct {
-	ID      int32
-	Index   []byte
-	Message []byte
-	Pubkey  []byte
+	ID            int32
+	Index         []byte
+	Message       []byte
+	Pubkey        []byte
+	State         MessageSubmissionState
+	Nonce         *int64
+	TxHash        []byte
+	Error         *string
+	UpdatedAt     time.Time
+	VersionedHash []byte
+	BlockNumber   *int64
+	QueuedAt      time.Time
+}
+
+type MessageBlobTx struct {
+	Nonce      int64
+	TxHashes   [][]byte
+	Payloads   [][]byte
+	GasTipCap  int64
+	GasFeeCap  int64
+	BlobFeeCap int64
+	SentAt     time.Time
+	Carrier    MessageTxCarrier
 }
 
 type MessageBlobUpdate struct {
-	BlockHeight int64
+	BlockHeight    int64
+	FinalizedBlock int64
+	BlobIndex      int32
+}
+
+type MessageChainBlock struct {
+	BlockNumber int64
+	BlockHash   []byte
+	ParentHash  []byte
 }
 
 type MessageEnsSubdomain struct {
@@ -32,7 +341,26 @@ type MessageEnsSubdomain struct {
 	Address   string
 }
 
+type MessageFailedBlob struct {
+	VersionedHash []byte
+	BlockNumber   int64
+	BlobIndex     int32
+	Error         string
+	Attempts      int32
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type MessageIngestedBlob struct {
+	VersionedHash []byte
+	BlockNumber   int64
+	BlockHash     []byte
+	BlobIndex     int32
+}
+
 type MessagePubkey struct {
-	Pubkey     []byte
-	SubmitTime time.Time
+	Pubkey      []byte
+	SubmitTime  time.Time
+	BlockNumber *int64
 }
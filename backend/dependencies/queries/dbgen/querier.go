@@ -10,58 +10,276 @@ import (
 )
 
 type Querier interface {
+	//AddBackfillRange
+	//
+	//  INSERT INTO message.backfill_range (from_block, to_block) VALUES ($1, $2)
+	//  ON CONFLICT (from_block, to_block) DO NOTHING
+	AddBackfillRange(ctx context.Context, arg AddBackfillRangeParams) error
+	//AddBlobBatch
+	//
+	//  INSERT INTO message.blob_batch (versioned_hash, nonce, message_count, fill_ratio, wait_ms, trigger)
+	//  VALUES ($1, $2, $3, $4, $5, $6)
+	AddBlobBatch(ctx context.Context, arg AddBlobBatchParams) error
+	//AddBlobFee
+	//
+	//  INSERT INTO message.blob_fee (tx_hash, fee, submit_time) VALUES ($1, $2, $3)
+	AddBlobFee(ctx context.Context, arg AddBlobFeeParams) error
 	//AddBlobSubmission
 	//
-	//  INSERT INTO message.blob_submission (index, message, pubkey) VALUES ($1, $2, $3) RETURNING id, index, message, pubkey
+	//  INSERT INTO message.blob_submission (index, message, pubkey) VALUES ($1, $2, $3) RETURNING id, index, message, pubkey, state, nonce, tx_hash, error, updated_at, versioned_hash, block_number, queued_at
 	AddBlobSubmission(ctx context.Context, arg AddBlobSubmissionParams) (MessageBlobSubmission, error)
+	//AddBlobTx
+	//
+	//  INSERT INTO message.blob_tx (nonce, tx_hashes, payloads, gas_tip_cap, gas_fee_cap, blob_fee_cap, sent_at, carrier)
+	//  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	AddBlobTx(ctx context.Context, arg AddBlobTxParams) error
+	//AddChainBlock
+	//
+	//  INSERT INTO message.chain_block (block_number, block_hash, parent_hash) VALUES ($1, $2, $3)
+	//  ON CONFLICT (block_number) DO UPDATE SET block_hash = EXCLUDED.block_hash, parent_hash = EXCLUDED.parent_hash
+	AddChainBlock(ctx context.Context, arg AddChainBlockParams) error
+	//AddChainPubkey
+	//
+	//  INSERT INTO message.pubkey (pubkey, submit_time, block_number) VALUES ($1, $2, $3)
+	//  ON CONFLICT (pubkey) DO UPDATE SET block_number = COALESCE(pubkey.block_number, EXCLUDED.block_number)
+	AddChainPubkey(ctx context.Context, arg AddChainPubkeyParams) error
 	//AddENSSubdomain
 	//
 	//  INSERT INTO message.ens_subdomain (subdomain, address) VALUES ($1, $2)
 	AddENSSubdomain(ctx context.Context, arg AddENSSubdomainParams) error
+	//AddFailedBlob
+	//
+	//  INSERT INTO message.failed_blob (versioned_hash, block_number, blob_index, error, next_attempt_at)
+	//  VALUES ($1, $2, $3, $4, $5)
+	//  ON CONFLICT (versioned_hash) DO NOTHING
+	AddFailedBlob(ctx context.Context, arg AddFailedBlobParams) error
+	//AddIngestedBlob
+	//
+	//  INSERT INTO message.ingested_blob (versioned_hash, block_number, block_hash, blob_index) VALUES ($1, $2, $3, $4)
+	//  ON CONFLICT (versioned_hash) DO NOTHING
+	AddIngestedBlob(ctx context.Context, arg AddIngestedBlobParams) (int64, error)
 	//AddMessage
 	//
-	//  INSERT INTO message.blob (index, message, submit_time, needs_submission) VALUES ($1, $2, $3, $4)
-	//  ON CONFLICT (index) DO UPDATE SET submit_time = EXCLUDED.submit_time, needs_submission = EXCLUDED.needs_submission
-	//  RETURNING id, index, message, submit_time, needs_submission
+	//  INSERT INTO message.blob (
+	//    index, message, submit_time, state, submission_id,
+	//    block_number, block_hash, tx_hash, versioned_hash, blob_index, blob_position
+	//  ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	//  RETURNING id, index, message, submit_time, block_number, block_hash, tx_hash, versioned_hash, blob_position, state, submission_id, blob_index
 	AddMessage(ctx context.Context, arg AddMessageParams) (MessageBlob, error)
 	//AddPubkey
 	//
 	//  INSERT INTO message.pubkey (pubkey, submit_time) VALUES ($1, $2)
 	//  ON CONFLICT (pubkey) DO UPDATE SET submit_time = EXCLUDED.submit_time
-	//  RETURNING pubkey, submit_time
+	//  RETURNING pubkey, submit_time, block_number
 	AddPubkey(ctx context.Context, arg AddPubkeyParams) (MessagePubkey, error)
-	//GetBlobSubmissions
+	//ClaimBlobSubmissions
+	//
+	//  SELECT id, index, message, pubkey, state, nonce, tx_hash, error, updated_at, versioned_hash, block_number, queued_at FROM message.blob_submission WHERE state = 'queued' ORDER BY id FOR UPDATE SKIP LOCKED
+	ClaimBlobSubmissions(ctx context.Context) ([]MessageBlobSubmission, error)
+	//CompactConfirmedBlobSubmissions
+	//
+	//  UPDATE message.blob_submission SET index = '', message = '', pubkey = ''
+	//  WHERE state IN ('confirmed', 'finalized') AND updated_at < $1 AND octet_length(message) > 0
+	CompactConfirmedBlobSubmissions(ctx context.Context, updatedAt time.Time) error
+	//CompleteBackfillRange
+	//
+	//  UPDATE message.backfill_range SET completed_at = NOW() WHERE from_block = $1 AND to_block = $2
+	CompleteBackfillRange(ctx context.Context, arg CompleteBackfillRangeParams) error
+	//ConfirmBlobSubmissions
 	//
-	//  SELECT id, index, message, pubkey FROM message.blob_submission
-	GetBlobSubmissions(ctx context.Context) ([]MessageBlobSubmission, error)
+	//  UPDATE message.blob_submission SET state = 'confirmed', tx_hash = $2, block_number = $3, updated_at = NOW()
+	//  WHERE nonce = $1 AND state = 'in_flight'
+	ConfirmBlobSubmissions(ctx context.Context, arg ConfirmBlobSubmissionsParams) error
+	//ConfirmMessage
+	//
+	//  UPDATE message.blob SET submit_time = $1, state = 'on_chain',
+	//    block_number = $2, block_hash = $3, tx_hash = $4,
+	//    versioned_hash = $5, blob_index = $6, blob_position = $7
+	//  WHERE id = (
+	//    SELECT pending.id FROM message.blob pending
+	//    WHERE pending.index = $8 AND pending.message = $9 AND pending.block_number IS NULL
+	//    ORDER BY pending.id LIMIT 1
+	//  )
+	ConfirmMessage(ctx context.Context, arg ConfirmMessageParams) (int64, error)
+	//FailBlobSubmission
+	//
+	//  UPDATE message.blob_submission SET state = 'failed', error = $2, updated_at = NOW() WHERE id = $1
+	FailBlobSubmission(ctx context.Context, arg FailBlobSubmissionParams) error
+	//FinalizeBlobSubmissions
+	//
+	//  UPDATE message.blob_submission SET state = 'finalized', updated_at = NOW()
+	//  WHERE state = 'confirmed' AND block_number <= $1
+	FinalizeBlobSubmissions(ctx context.Context, blockNumber *int64) error
+	//FinalizeMessages
+	//
+	//  UPDATE message.blob SET state = 'finalized' WHERE state = 'on_chain' AND block_number <= $1
+	FinalizeMessages(ctx context.Context, blockNumber *int64) error
+	//GetBlobBatch
+	//
+	//  SELECT id, versioned_hash, nonce, message_count, fill_ratio, wait_ms, trigger, created_at FROM message.blob_batch WHERE versioned_hash = $1 ORDER BY id DESC LIMIT 1
+	GetBlobBatch(ctx context.Context, versionedHash []byte) (MessageBlobBatch, error)
+	//GetBlobFeesSince
+	//
+	//  SELECT COALESCE(SUM(fee), 0)::BIGINT AS total FROM message.blob_fee WHERE submit_time > $1
+	GetBlobFeesSince(ctx context.Context, submitTime time.Time) (int64, error)
+	//GetBlobSubmission
+	//
+	//  SELECT id, index, message, pubkey, state, nonce, tx_hash, error, updated_at, versioned_hash, block_number, queued_at FROM message.blob_submission WHERE id = $1
+	GetBlobSubmission(ctx context.Context, id int32) (MessageBlobSubmission, error)
+	//GetBlobTxCarriers
+	//
+	//  SELECT DISTINCT carrier FROM message.blob_tx
+	GetBlobTxCarriers(ctx context.Context) ([]MessageTxCarrier, error)
+	//GetBlobTxs
+	//
+	//  SELECT nonce, tx_hashes, payloads, gas_tip_cap, gas_fee_cap, blob_fee_cap, sent_at, carrier FROM message.blob_tx ORDER BY nonce
+	GetBlobTxs(ctx context.Context) ([]MessageBlobTx, error)
 	//GetBlobUpdate
 	//
-	//  SELECT block_height FROM message.blob_update LIMIT 1
-	GetBlobUpdate(ctx context.Context) (int64, error)
+	//  SELECT block_height, blob_index FROM message.blob_update LIMIT 1
+	GetBlobUpdate(ctx context.Context) (GetBlobUpdateRow, error)
+	//GetDueFailedBlobs
+	//
+	//  SELECT versioned_hash, block_number, blob_index, error, attempts, next_attempt_at, created_at, updated_at FROM message.failed_blob WHERE next_attempt_at <= $1 ORDER BY next_attempt_at LIMIT 50
+	GetDueFailedBlobs(ctx context.Context, nextAttemptAt time.Time) ([]MessageFailedBlob, error)
 	//GetENSSubdomainByAddress
 	//
 	//  SELECT subdomain, address FROM message.ens_subdomain WHERE address = $1
 	GetENSSubdomainByAddress(ctx context.Context, address string) (MessageEnsSubdomain, error)
 	//GetMessagesByIndex
 	//
-	//  SELECT id, index, message, submit_time, needs_submission FROM message.blob WHERE index = $1
-	GetMessagesByIndex(ctx context.Context, index []byte) ([]MessageBlob, error)
-	//GetPubkeysSince
+	//  SELECT id, index, message, submit_time, block_number, block_hash, tx_hash, versioned_hash, blob_position, state, submission_id, blob_index FROM message.blob WHERE index = $1 AND state::TEXT = ANY($4::TEXT[])
+	//  ORDER BY block_number NULLS LAST, blob_index, blob_position, id
+	//  LIMIT $2 OFFSET $3
+	GetMessagesByIndex(ctx context.Context, arg GetMessagesByIndexParams) ([]MessageBlob, error)
+	//GetMessagesByIndexes
+	//
+	//  SELECT id, index, message, submit_time, block_number, block_hash, tx_hash, versioned_hash, blob_position, state, submission_id, blob_index FROM message.blob
+	//  WHERE index = ANY($1::BYTEA[]) AND state::TEXT = ANY($2::TEXT[])
+	//  ORDER BY index, block_number NULLS LAST, blob_index, blob_position, id
+	GetMessagesByIndexes(ctx context.Context, arg GetMessagesByIndexesParams) ([]MessageBlob, error)
+	//GetNextBlobTxNonce
 	//
-	//  SELECT pubkey, submit_time FROM message.pubkey WHERE submit_time > $1 LIMIT 1000
-	GetPubkeysSince(ctx context.Context, submitTime time.Time) ([]MessagePubkey, error)
-	//RemoveBlobSubmission
+	//  SELECT COALESCE(MAX(nonce) + 1, 0)::BIGINT AS nonce FROM message.blob_tx
+	GetNextBlobTxNonce(ctx context.Context) (int64, error)
+	//GetPendingBackfillRanges
 	//
-	//  DELETE FROM message.blob_submission WHERE id = $1
-	RemoveBlobSubmission(ctx context.Context, id int32) error
+	//  SELECT from_block, to_block, completed_at FROM message.backfill_range
+	//  WHERE completed_at IS NULL AND from_block >= $1 AND to_block <= $2
+	//  ORDER BY from_block
+	GetPendingBackfillRanges(ctx context.Context, arg GetPendingBackfillRangesParams) ([]MessageBackfillRange, error)
+	//GetPubkeysPage
+	//
+	//  SELECT pubkey, submit_time, block_number FROM message.pubkey
+	//  WHERE (submit_time, pubkey) > ($1, $2::BYTEA)
+	//    AND ($3::TIMESTAMP IS NULL OR submit_time > $3)
+	//    AND ($4::BIGINT IS NULL OR block_number >= $4)
+	//  ORDER BY submit_time, pubkey
+	//  LIMIT $5
+	GetPubkeysPage(ctx context.Context, arg GetPubkeysPageParams) ([]MessagePubkey, error)
+	//GetRecentChainBlocks
+	//
+	//  SELECT block_number, block_hash, parent_hash FROM message.chain_block ORDER BY block_number DESC LIMIT $1
+	GetRecentChainBlocks(ctx context.Context, limit int32) ([]MessageChainBlock, error)
+	//ListFailedBlobs
+	//
+	//  SELECT versioned_hash, block_number, blob_index, error, attempts, next_attempt_at, created_at, updated_at FROM message.failed_blob ORDER BY block_number, blob_index LIMIT $1 OFFSET $2
+	ListFailedBlobs(ctx context.Context, arg ListFailedBlobsParams) ([]MessageFailedBlob, error)
+	//MarkBlobSubmissionsInFlight
+	//
+	//  UPDATE message.blob_submission SET state = 'in_flight', nonce = $1, tx_hash = $2, versioned_hash = $3, updated_at = NOW()
+	//  WHERE id = ANY($4::INT[])
+	MarkBlobSubmissionsInFlight(ctx context.Context, arg MarkBlobSubmissionsInFlightParams) error
+	//MarkMessagesSubmitted
+	//
+	//  UPDATE message.blob SET state = 'submitted' WHERE submission_id = ANY($1::INT[]) AND state = 'pending'
+	MarkMessagesSubmitted(ctx context.Context, submissionIds []int32) error
+	//PublishBlobSubmissions
+	//
+	//  UPDATE message.blob_submission SET state = 'confirmed', versioned_hash = $1, updated_at = NOW()
+	//  WHERE id = ANY($2::INT[])
+	PublishBlobSubmissions(ctx context.Context, arg PublishBlobSubmissionsParams) error
+	//RemoveBlobTx
+	//
+	//  DELETE FROM message.blob_tx WHERE nonce = $1
+	RemoveBlobTx(ctx context.Context, nonce int64) error
+	//RemoveChainBlocksBefore
+	//
+	//  DELETE FROM message.chain_block WHERE block_number < $1
+	RemoveChainBlocksBefore(ctx context.Context, blockNumber int64) error
+	//RemoveChainBlocksFrom
+	//
+	//  DELETE FROM message.chain_block WHERE block_number >= $1
+	RemoveChainBlocksFrom(ctx context.Context, blockNumber int64) error
+	//RemoveFailedBlob
+	//
+	//  DELETE FROM message.failed_blob WHERE versioned_hash = $1
+	RemoveFailedBlob(ctx context.Context, versionedHash []byte) error
+	//RemoveFailedBlobsFromBlock
+	//
+	//  DELETE FROM message.failed_blob WHERE block_number >= $1
+	RemoveFailedBlobsFromBlock(ctx context.Context, blockNumber int64) error
+	//RemoveIngestedBlobsFromBlock
+	//
+	//  DELETE FROM message.ingested_blob WHERE block_number >= $1
+	RemoveIngestedBlobsFromBlock(ctx context.Context, blockNumber int64) error
+	//RemoveMessagesFromBlock
+	//
+	//  DELETE FROM message.blob WHERE block_number >= $1
+	RemoveMessagesFromBlock(ctx context.Context, blockNumber *int64) error
+	//RemovePendingMessages
+	//
+	//  DELETE FROM message.blob WHERE submission_id = $1 AND state = 'pending'
+	RemovePendingMessages(ctx context.Context, submissionID *int32) error
+	//RequeueBlobSubmissions
+	//
+	//  UPDATE message.blob_submission SET state = 'queued', nonce = NULL, tx_hash = NULL, versioned_hash = NULL, updated_at = NOW()
+	//  WHERE nonce = $1 AND state = 'in_flight'
+	RequeueBlobSubmissions(ctx context.Context, nonce *int64) error
+	//RequeueMessages
+	//
+	//  UPDATE message.blob SET state = 'pending'
+	//  WHERE state = 'submitted' AND submission_id IN (
+	//    SELECT id FROM message.blob_submission WHERE nonce = $1 AND state = 'in_flight'
+	//  )
+	RequeueMessages(ctx context.Context, nonce *int64) error
+	//RetryFailedBlob
+	//
+	//  UPDATE message.failed_blob SET error = $2, attempts = attempts + 1, next_attempt_at = $3, updated_at = NOW()
+	//  WHERE versioned_hash = $1
+	RetryFailedBlob(ctx context.Context, arg RetryFailedBlobParams) error
+	//RewindBlobUpdate
+	//
+	//  UPDATE message.blob_update SET block_height = LEAST(block_height, $1),
+	//    blob_index = CASE WHEN block_height >= $1 THEN 0 ELSE blob_index END
+	RewindBlobUpdate(ctx context.Context, blockHeight int64) error
+	//SetBlobSubmissionsTxHash
+	//
+	//  UPDATE message.blob_submission SET tx_hash = $2, updated_at = NOW() WHERE nonce = $1 AND state = 'in_flight'
+	SetBlobSubmissionsTxHash(ctx context.Context, arg SetBlobSubmissionsTxHashParams) error
 	//SetBlobUpdate
 	//
 	//  INSERT INTO message.blob_update (block_height) VALUES ($1)
 	SetBlobUpdate(ctx context.Context, blockHeight int64) error
+	//SetFinalizedBlock
+	//
+	//  UPDATE message.blob_update SET finalized_block = $1
+	SetFinalizedBlock(ctx context.Context, finalizedBlock int64) error
+	//UnconfirmMessagesFromBlock
+	//
+	//  UPDATE message.blob SET state = 'submitted', block_number = NULL, block_hash = NULL, tx_hash = NULL,
+	//    versioned_hash = NULL, blob_index = NULL, blob_position = NULL
+	//  WHERE block_number >= $1 AND submission_id IS NOT NULL
+	UnconfirmMessagesFromBlock(ctx context.Context, blockNumber *int64) error
+	//UpdateBlobTx
+	//
+	//  UPDATE message.blob_tx SET tx_hashes = $2, gas_tip_cap = $3, gas_fee_cap = $4, blob_fee_cap = $5, sent_at = $6
+	//  WHERE nonce = $1
+	UpdateBlobTx(ctx context.Context, arg UpdateBlobTxParams) error
 	//UpdateBlobUpdate
 	//
-	//  UPDATE message.blob_update SET block_height = $1
-	UpdateBlobUpdate(ctx context.Context, blockHeight int64) error
+	//  UPDATE message.blob_update SET block_height = $1, blob_index = $2
+	UpdateBlobUpdate(ctx context.Context, arg UpdateBlobUpdateParams) error
 }
 
 var _ Querier = (*Queries)(nil)
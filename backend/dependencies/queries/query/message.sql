@@ -3,37 +3,211 @@ INSERT INTO message.pubkey (pubkey, submit_time) VALUES ($1, $2)
 ON CONFLICT (pubkey) DO UPDATE SET submit_time = EXCLUDED.submit_time 
 RETURNING *;
 
--- name: GetPubkeysSince :many
-SELECT * FROM message.pubkey WHERE submit_time > $1 LIMIT 1000;
+-- name: AddChainPubkey :exec
+INSERT INTO message.pubkey (pubkey, submit_time, block_number) VALUES ($1, $2, $3)
+ON CONFLICT (pubkey) DO UPDATE SET block_number = COALESCE(pubkey.block_number, EXCLUDED.block_number);
+
+-- name: GetPubkeysPage :many
+SELECT * FROM message.pubkey
+WHERE (submit_time, pubkey) > (sqlc.arg(after_time), sqlc.arg(after_pubkey)::BYTEA)
+  AND (sqlc.narg(since)::TIMESTAMP IS NULL OR submit_time > sqlc.narg(since))
+  AND (sqlc.narg(from_block)::BIGINT IS NULL OR block_number >= sqlc.narg(from_block))
+ORDER BY submit_time, pubkey
+LIMIT sqlc.arg(page_size);
 
 -- name: AddMessage :one
-INSERT INTO message.blob (index, message, submit_time, needs_submission) VALUES ($1, $2, $3, $4) 
-ON CONFLICT (index) DO UPDATE SET submit_time = EXCLUDED.submit_time, needs_submission = EXCLUDED.needs_submission 
+INSERT INTO message.blob (
+  index, message, submit_time, state, submission_id,
+  block_number, block_hash, tx_hash, versioned_hash, blob_index, blob_position
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *;
 
+-- name: ConfirmMessage :execrows
+UPDATE message.blob SET submit_time = sqlc.arg(submit_time), state = 'on_chain',
+  block_number = sqlc.arg(block_number), block_hash = sqlc.arg(block_hash), tx_hash = sqlc.arg(tx_hash),
+  versioned_hash = sqlc.arg(versioned_hash), blob_index = sqlc.arg(blob_index), blob_position = sqlc.arg(blob_position)
+WHERE id = (
+  SELECT pending.id FROM message.blob pending
+  WHERE pending.index = sqlc.arg(index) AND pending.message = sqlc.arg(message) AND pending.block_number IS NULL
+  ORDER BY pending.id LIMIT 1
+);
+
 -- name: GetMessagesByIndex :many
-SELECT * FROM message.blob WHERE index = $1;
+SELECT * FROM message.blob WHERE index = $1 AND state::TEXT = ANY(sqlc.arg(states)::TEXT[])
+ORDER BY block_number NULLS LAST, blob_index, blob_position, id
+LIMIT $2 OFFSET $3;
+
+-- name: MarkMessagesSubmitted :exec
+UPDATE message.blob SET state = 'submitted' WHERE submission_id = ANY(sqlc.arg(submission_ids)::INT[]) AND state = 'pending';
+
+-- name: RemovePendingMessages :exec
+DELETE FROM message.blob WHERE submission_id = $1 AND state = 'pending';
+
+-- name: RequeueMessages :exec
+UPDATE message.blob SET state = 'pending'
+WHERE state = 'submitted' AND submission_id IN (
+  SELECT id FROM message.blob_submission WHERE nonce = $1 AND state = 'in_flight'
+);
+
+-- name: FinalizeMessages :exec
+UPDATE message.blob SET state = 'finalized' WHERE state = 'on_chain' AND block_number <= $1;
+
+-- name: UnconfirmMessagesFromBlock :exec
+UPDATE message.blob SET state = 'submitted', block_number = NULL, block_hash = NULL, tx_hash = NULL,
+  versioned_hash = NULL, blob_index = NULL, blob_position = NULL
+WHERE block_number >= $1 AND submission_id IS NOT NULL;
+
+-- name: RemoveMessagesFromBlock :exec
+DELETE FROM message.blob WHERE block_number >= $1;
+
+-- name: AddIngestedBlob :execrows
+INSERT INTO message.ingested_blob (versioned_hash, block_number, block_hash, blob_index) VALUES ($1, $2, $3, $4)
+ON CONFLICT (versioned_hash) DO NOTHING;
+
+-- name: RemoveIngestedBlobsFromBlock :exec
+DELETE FROM message.ingested_blob WHERE block_number >= $1;
+
+-- name: AddChainBlock :exec
+INSERT INTO message.chain_block (block_number, block_hash, parent_hash) VALUES ($1, $2, $3)
+ON CONFLICT (block_number) DO UPDATE SET block_hash = EXCLUDED.block_hash, parent_hash = EXCLUDED.parent_hash;
+
+-- name: GetRecentChainBlocks :many
+SELECT * FROM message.chain_block ORDER BY block_number DESC LIMIT $1;
+
+-- name: RemoveChainBlocksFrom :exec
+DELETE FROM message.chain_block WHERE block_number >= $1;
+
+-- name: RemoveChainBlocksBefore :exec
+DELETE FROM message.chain_block WHERE block_number < $1;
 
 -- name: AddBlobSubmission :one
 INSERT INTO message.blob_submission (index, message, pubkey) VALUES ($1, $2, $3) RETURNING *;
 
--- name: GetBlobSubmissions :many
-SELECT * FROM message.blob_submission;
+-- name: ClaimBlobSubmissions :many
+SELECT * FROM message.blob_submission WHERE state = 'queued' ORDER BY id FOR UPDATE SKIP LOCKED;
+
+-- name: MarkBlobSubmissionsInFlight :exec
+UPDATE message.blob_submission SET state = 'in_flight', nonce = $1, tx_hash = $2, versioned_hash = $3, updated_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::INT[]);
+
+-- name: PublishBlobSubmissions :exec
+UPDATE message.blob_submission SET state = 'confirmed', versioned_hash = $1, updated_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::INT[]);
+
+-- name: SetBlobSubmissionsTxHash :exec
+UPDATE message.blob_submission SET tx_hash = $2, updated_at = NOW() WHERE nonce = $1 AND state = 'in_flight';
+
+-- name: ConfirmBlobSubmissions :exec
+UPDATE message.blob_submission SET state = 'confirmed', tx_hash = $2, block_number = $3, updated_at = NOW()
+WHERE nonce = $1 AND state = 'in_flight';
+
+-- name: FinalizeBlobSubmissions :exec
+UPDATE message.blob_submission SET state = 'finalized', updated_at = NOW()
+WHERE state = 'confirmed' AND block_number <= $1;
+
+-- name: RequeueBlobSubmissions :exec
+UPDATE message.blob_submission SET state = 'queued', nonce = NULL, tx_hash = NULL, versioned_hash = NULL, updated_at = NOW()
+WHERE nonce = $1 AND state = 'in_flight';
+
+-- name: FailBlobSubmission :exec
+UPDATE message.blob_submission SET state = 'failed', error = $2, updated_at = NOW() WHERE id = $1;
+
+-- name: CompactConfirmedBlobSubmissions :exec
+UPDATE message.blob_submission SET index = '', message = '', pubkey = ''
+WHERE state IN ('confirmed', 'finalized') AND updated_at < $1 AND octet_length(message) > 0;
 
--- name: RemoveBlobSubmission :exec
-DELETE FROM message.blob_submission WHERE id = $1;
+-- name: GetBlobSubmission :one
+SELECT * FROM message.blob_submission WHERE id = $1;
+
+-- name: AddBlobBatch :exec
+INSERT INTO message.blob_batch (versioned_hash, nonce, message_count, fill_ratio, wait_ms, trigger)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetBlobBatch :one
+SELECT * FROM message.blob_batch WHERE versioned_hash = $1 ORDER BY id DESC LIMIT 1;
+
+-- name: AddBlobTx :exec
+INSERT INTO message.blob_tx (nonce, tx_hashes, payloads, gas_tip_cap, gas_fee_cap, blob_fee_cap, sent_at, carrier)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: UpdateBlobTx :exec
+UPDATE message.blob_tx SET tx_hashes = $2, gas_tip_cap = $3, gas_fee_cap = $4, blob_fee_cap = $5, sent_at = $6
+WHERE nonce = $1;
+
+-- name: GetBlobTxs :many
+SELECT * FROM message.blob_tx ORDER BY nonce;
+
+-- name: GetBlobTxCarriers :many
+SELECT DISTINCT carrier FROM message.blob_tx;
+
+-- name: GetNextBlobTxNonce :one
+SELECT COALESCE(MAX(nonce) + 1, 0)::BIGINT AS nonce FROM message.blob_tx;
+
+-- name: RemoveBlobTx :exec
+DELETE FROM message.blob_tx WHERE nonce = $1;
 
 -- name: SetBlobUpdate :exec
 INSERT INTO message.blob_update (block_height) VALUES ($1);
 
 -- name: UpdateBlobUpdate :exec
-UPDATE message.blob_update SET block_height = $1;
+UPDATE message.blob_update SET block_height = $1, blob_index = $2;
 
 -- name: GetBlobUpdate :one
-SELECT * FROM message.blob_update LIMIT 1;
+SELECT block_height, blob_index FROM message.blob_update LIMIT 1;
+
+-- name: RewindBlobUpdate :exec
+UPDATE message.blob_update SET block_height = LEAST(block_height, $1),
+  blob_index = CASE WHEN block_height >= $1 THEN 0 ELSE blob_index END;
+
+-- name: SetFinalizedBlock :exec
+UPDATE message.blob_update SET finalized_block = $1;
 
 -- name: AddENSSubdomain :exec
 INSERT INTO message.ens_subdomain (subdomain, address) VALUES ($1, $2);
 
 -- name: GetENSSubdomainByAddress :one
 SELECT * FROM message.ens_subdomain WHERE address = $1;
+
+-- name: AddBlobFee :exec
+INSERT INTO message.blob_fee (tx_hash, fee, submit_time) VALUES ($1, $2, $3);
+
+-- name: GetBlobFeesSince :one
+SELECT COALESCE(SUM(fee), 0)::BIGINT AS total FROM message.blob_fee WHERE submit_time > $1;
+
+-- name: AddBackfillRange :exec
+INSERT INTO message.backfill_range (from_block, to_block) VALUES ($1, $2)
+ON CONFLICT (from_block, to_block) DO NOTHING;
+
+-- name: GetPendingBackfillRanges :many
+SELECT * FROM message.backfill_range
+WHERE completed_at IS NULL AND from_block >= sqlc.arg(from_block) AND to_block <= sqlc.arg(to_block)
+ORDER BY from_block;
+
+-- name: CompleteBackfillRange :exec
+UPDATE message.backfill_range SET completed_at = NOW() WHERE from_block = $1 AND to_block = $2;
+
+-- name: AddFailedBlob :exec
+INSERT INTO message.failed_blob (versioned_hash, block_number, blob_index, error, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (versioned_hash) DO NOTHING;
+
+-- name: GetDueFailedBlobs :many
+SELECT * FROM message.failed_blob WHERE next_attempt_at <= $1 ORDER BY next_attempt_at LIMIT 50;
+
+-- name: RetryFailedBlob :exec
+UPDATE message.failed_blob SET error = $2, attempts = attempts + 1, next_attempt_at = $3, updated_at = NOW()
+WHERE versioned_hash = $1;
+
+-- name: RemoveFailedBlob :exec
+DELETE FROM message.failed_blob WHERE versioned_hash = $1;
+
+-- name: RemoveFailedBlobsFromBlock :exec
+DELETE FROM message.failed_blob WHERE block_number >= $1;
+
+-- name: ListFailedBlobs :many
+SELECT * FROM message.failed_blob ORDER BY block_number, blob_index LIMIT $1 OFFSET $2;
+
+-- name: GetMessagesByIndexes :many
+SELECT * FROM message.blob
+WHERE index = ANY(sqlc.arg(indexes)::BYTEA[]) AND state::TEXT = ANY(sqlc.arg(states)::TEXT[])
+ORDER BY index, block_number NULLS LAST, blob_index, blob_position, id;
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity ^0.8.25;
+
+import {Script} from "forge-std/Script.sol";
+import {BlobInbox} from "../src/BlobInbox.sol";
+
+contract Deploy is Script {
+    BlobInbox public inbox;
+
+    function setUp() public {}
+
+    function run() public {
+        vm.startBroadcast();
+
+        inbox = new BlobInbox();
+
+        vm.stopBroadcast();
+    }
+}
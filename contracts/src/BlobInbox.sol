@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.25;
+
+/// @notice Announces the blobs of OnlyDanks relay transactions so indexers can
+/// find them through logs instead of downloading every blob on the network
+contract BlobInbox {
+    /// @notice Emitted once for every blob carried by the calling transaction
+    /// @param sender The relay that sent the blob transaction
+    /// @param versionedHash The versioned hash of the blob
+    /// @param version The payload protocol version of the blob
+    event BlobAnnounced(address indexed sender, bytes32 indexed versionedHash, uint8 version);
+
+    /// @notice Thrown when the calling transaction carries no blobs
+    error NoBlobs();
+
+    /// @notice Announces every blob of the calling transaction
+    /// @param version The payload protocol version of the blobs
+    function announce(uint8 version) external {
+        uint256 i = 0;
+        for (bytes32 versionedHash = blobhash(i); versionedHash != 0; versionedHash = blobhash(i)) {
+            emit BlobAnnounced(msg.sender, versionedHash, version);
+            i++;
+        }
+        if (i == 0) {
+            revert NoBlobs();
+        }
+    }
+}
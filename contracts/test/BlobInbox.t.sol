@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity ^0.8.25;
+
+import {Test} from "forge-std/Test.sol";
+import {BlobInbox} from "../src/BlobInbox.sol";
+
+contract BlobInboxTest is Test {
+    event BlobAnnounced(address indexed sender, bytes32 indexed versionedHash, uint8 version);
+
+    BlobInbox inbox;
+
+    function setUp() public {
+        inbox = new BlobInbox();
+    }
+
+    function test_Announce() public {
+        bytes32[] memory hashes = new bytes32[](2);
+        hashes[0] = bytes32(uint256(0x01) << 248 | 1);
+        hashes[1] = bytes32(uint256(0x01) << 248 | 2);
+        vm.blobhashes(hashes);
+
+        vm.expectEmit(true, true, false, true);
+        emit BlobAnnounced(address(this), hashes[0], 1);
+        vm.expectEmit(true, true, false, true);
+        emit BlobAnnounced(address(this), hashes[1], 1);
+        inbox.announce(1);
+    }
+
+    function test_AnnounceWithoutBlobs() public {
+        vm.expectRevert(BlobInbox.NoBlobs.selector);
+        inbox.announce(1);
+    }
+}
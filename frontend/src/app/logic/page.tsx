@@ -184,16 +184,23 @@ export default function LogicPage() {
 
 	const readMessages = async () => {
 		if (!keyPair) return;
-		const response = await fetch(`https://proto-dankmessaging-production.up.railway.app/keys?since=1970-01-01T00:00:00.000Z`, {
-			method: 'GET',
-			headers: {
-				'Content-Type': 'application/json',
-			},
-		});
-		if (!response.ok) {
-			throw new Error(`HTTP error! status: ${response.status}`);
+		const keys: string[] = [];
+		let keysQuery = "since=1970-01-01T00:00:00.000Z";
+		for (;;) {
+			const response = await fetch(`https://proto-dankmessaging-production.up.railway.app/keys?${keysQuery}`, {
+				method: 'GET',
+				headers: {
+					'Content-Type': 'application/json',
+				},
+			});
+			if (!response.ok) {
+				throw new Error(`HTTP error! status: ${response.status}`);
+			}
+			const page: { keys: { pubkey: string }[], next_cursor?: string } = await response.json();
+			keys.push(...page.keys.map(key => key.pubkey));
+			if (!page.next_cursor) break;
+			keysQuery = `cursor=${page.next_cursor}`;
 		}
-		const keys = await response.json();
 		console.log("Keys:", keys);
 		const decryptedMessages: { message: string, submit_time: string, sender: string }[] = [];
 		for (const key of keys) {
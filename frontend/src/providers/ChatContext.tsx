@@ -180,16 +180,23 @@ export const ChatProvider = ({ children }: { children: React.ReactNode }) => {
 	console.log("publicKey", `0x${keyPair.getPublic().getX().toString("hex")}${keyPair.getPublic().getY().toString("hex")}`);
 
 	/* ------------------------ Fetch All Ephemeral Keys ------------------------ */
-	const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL}/keys?since=1970-01-01T00:00:00.000Z&limit=100`, {
-		method: 'GET',
-		headers: {
-			'Content-Type': 'application/json',
-		},
-	});
-	if (!response.ok) {
-		throw new Error(`HTTP error! status: ${response.status}`);
+	const keys: string[] = [];
+	let keysQuery = "since=1970-01-01T00:00:00.000Z";
+	for (;;) {
+		const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL}/keys?${keysQuery}`, {
+			method: 'GET',
+			headers: {
+				'Content-Type': 'application/json',
+			},
+		});
+		if (!response.ok) {
+			throw new Error(`HTTP error! status: ${response.status}`);
+		}
+		const page: { keys: { pubkey: string }[], next_cursor?: string } = await response.json();
+		keys.push(...page.keys.map(key => key.pubkey));
+		if (!page.next_cursor) break;
+		keysQuery = `cursor=${page.next_cursor}`;
 	}
-	const keys = await response.json();
 
 	/* ------------------------------- Check Keys ------------------------------- */
 	const decryptedMessages: { message: string, submit_time: string, sender: string, name: string }[] = [];